@@ -0,0 +1,231 @@
+// Package analytics collects token shape statistics of a text.
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Report contains statistics of a text.
+type Report struct {
+	// Bytes is a number of bytes.
+	Bytes int64 `json:"bytes"`
+	// Chars is a number of utf-8 characters.
+	Chars int64 `json:"chars"`
+	// Lines is a number of newlines, like wc(1) counts them.
+	Lines int64 `json:"lines"`
+	// Sentences is a number of tokens ending with '.', '!' or '?'.
+	Sentences int64 `json:"sentences"`
+	// Tokens is a number of whitespace separated tokens.
+	Tokens int64 `json:"tokens"`
+	// Words is a number of tokens made of letters, e.g. "word" or "don't".
+	Words int64 `json:"words"`
+	// Numeric is a number of tokens made of digits, e.g. "42" or "3.14".
+	Numeric int64 `json:"numeric"`
+	// Alphanumeric is a number of tokens made of letters and digits, e.g. "v2".
+	Alphanumeric int64 `json:"alphanumeric"`
+	// Punctuation is a number of tokens without letters and digits, e.g. "--".
+	Punctuation int64 `json:"punctuation"`
+	// WordLengths is a distribution of words by a number of characters.
+	WordLengths map[int]int64 `json:"word_lengths"`
+}
+
+// New returns an empty report.
+func New() *Report {
+	return &Report{
+		WordLengths: map[int]int64{},
+	}
+}
+
+// Batch returns a report of the batch.
+func Batch(batch []byte) *Report {
+	r := New()
+	r.Bytes = int64(len(batch))
+	r.Chars = int64(utf8.RuneCount(batch))
+
+	tokenStart := -1
+	for i, c := range batch {
+		if c == '\n' {
+			r.Lines++
+		}
+
+		if !isSpace(c) {
+			if tokenStart == -1 {
+				tokenStart = i
+			}
+			continue
+		}
+
+		if tokenStart != -1 {
+			r.addToken(batch[tokenStart:i])
+			tokenStart = -1
+		}
+	}
+
+	if tokenStart != -1 {
+		r.addToken(batch[tokenStart:])
+	}
+
+	return r
+}
+
+func (r *Report) addToken(token []byte) {
+	r.Tokens++
+
+	start, end := 0, len(token)
+	for start < end && isPunct(token[start]) {
+		start++
+	}
+	for end > start && isPunct(token[end-1]) {
+		end--
+	}
+
+	core := token[start:end]
+	if len(core) == 0 {
+		r.Punctuation++
+		return
+	}
+
+	for _, c := range token[end:] {
+		if c == '.' || c == '!' || c == '?' {
+			r.Sentences++
+			break
+		}
+	}
+
+	var letters, digits bool
+	for _, c := range core {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case isPunct(c):
+		default:
+			// NOTE: non-ascii bytes are treated as letters
+			letters = true
+		}
+	}
+
+	switch {
+	case letters && digits:
+		r.Alphanumeric++
+	case digits:
+		r.Numeric++
+	default:
+		r.Words++
+		r.WordLengths[utf8.RuneCount(core)]++
+	}
+}
+
+// Merge adds other report to r.
+func (r *Report) Merge(other *Report) {
+	r.Bytes += other.Bytes
+	r.Chars += other.Chars
+	r.Lines += other.Lines
+	r.Sentences += other.Sentences
+	r.Tokens += other.Tokens
+	r.Words += other.Words
+	r.Numeric += other.Numeric
+	r.Alphanumeric += other.Alphanumeric
+	r.Punctuation += other.Punctuation
+	for length, n := range other.WordLengths {
+		r.WordLengths[length] += n
+	}
+}
+
+// WriteJSON writes the report to w as json.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+const barWidth = 40
+
+// WriteText writes the report to w with text histograms.
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w,
+		"bytes: %d\nchars: %d\nlines: %d\nsentences: %d\ntokens: %d\n",
+		r.Bytes, r.Chars, r.Lines, r.Sentences, r.Tokens,
+	); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "\ntoken shapes:"); err != nil {
+		return err
+	}
+	if err := writeHistogram(w, []bar{
+		{"words", r.Words},
+		{"numeric", r.Numeric},
+		{"alphanumeric", r.Alphanumeric},
+		{"punctuation", r.Punctuation},
+	}); err != nil {
+		return err
+	}
+
+	lengths := make([]int, 0, len(r.WordLengths))
+	for length := range r.WordLengths {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+
+	bars := make([]bar, 0, len(lengths))
+	for _, length := range lengths {
+		bars = append(bars, bar{fmt.Sprint(length), r.WordLengths[length]})
+	}
+
+	if _, err := fmt.Fprintln(w, "\nword lengths:"); err != nil {
+		return err
+	}
+	return writeHistogram(w, bars)
+}
+
+type bar struct {
+	label string
+	value int64
+}
+
+func writeHistogram(w io.Writer, bars []bar) error {
+	var max int64
+	labelWidth := 0
+	for _, b := range bars {
+		if b.value > max {
+			max = b.value
+		}
+		if len(b.label) > labelWidth {
+			labelWidth = len(b.label)
+		}
+	}
+
+	for _, b := range bars {
+		width := 0
+		if max > 0 {
+			width = int(b.value * barWidth / max)
+		}
+		if _, err := fmt.Fprintf(w, "%*s | %-*s %d\n",
+			labelWidth, b.label, barWidth, strings.Repeat("#", width), b.value,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	default:
+		return false
+	}
+}
+
+func isPunct(c byte) bool {
+	return c >= '!' && c <= '/' ||
+		c >= ':' && c <= '@' ||
+		c >= '[' && c <= '`' ||
+		c >= '{' && c <= '~'
+}
@@ -0,0 +1,48 @@
+package analytics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Batch(t *testing.T) {
+	r := Batch([]byte("The cat sat on the mat.\nIt costs 3.14, v2 -- isn't it?\n"))
+
+	assert.Equal(t, int64(55), r.Bytes)
+	assert.Equal(t, int64(2), r.Lines)
+	assert.Equal(t, int64(2), r.Sentences)
+	assert.Equal(t, int64(13), r.Tokens)
+	assert.Equal(t, int64(10), r.Words)
+	assert.Equal(t, int64(1), r.Numeric)
+	assert.Equal(t, int64(1), r.Alphanumeric)
+	assert.Equal(t, int64(1), r.Punctuation)
+	assert.Equal(t, map[int]int64{
+		2: 3,
+		3: 5,
+		5: 2,
+	}, r.WordLengths)
+}
+
+func Test_Merge(t *testing.T) {
+	r := New()
+	r.Merge(Batch([]byte("one two\n")))
+	r.Merge(Batch([]byte("three 4\n")))
+
+	assert.Equal(t, int64(2), r.Lines)
+	assert.Equal(t, int64(4), r.Tokens)
+	assert.Equal(t, int64(3), r.Words)
+	assert.Equal(t, int64(1), r.Numeric)
+	assert.Equal(t, map[int]int64{3: 2, 5: 1}, r.WordLengths)
+}
+
+func Test_WriteText(t *testing.T) {
+	r := Batch([]byte("a bb bb"))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, r.WriteText(buf))
+	assert.Contains(t, buf.String(), "       words | ######################################## 3\n")
+	assert.Contains(t, buf.String(), "1 | ####################                     1\n")
+	assert.Contains(t, buf.String(), "2 | ######################################## 2\n")
+}
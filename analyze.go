@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"text/tabwriter"
+
+	"github.com/ngalaiko/words/analytics"
+	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/lexical"
+)
+
+// maxWordLen is a max length of a word when all words are counted, longer
+// words are truncated.
+const maxWordLen = 64
+
+// analyze prints lexical diversity and readability metrics of every file.
+func analyze(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print metrics as json")
+	batchSize := byteSizeVar(flags, "batch-size", batchSizeUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: words analyze [-json] [-batch-size=auto] file...")
+	}
+
+	results := make(map[string]*lexical.Metrics, flags.NArg())
+	for _, filepath := range flags.Args() {
+		m, err := lexicalMetrics(filepath, int64(*batchSize))
+		if err != nil {
+			return err
+		}
+		results[filepath] = m
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "file\ttokens\ttypes\tttr\thapax\tyule k\tflesch")
+	for _, filepath := range flags.Args() {
+		m := results[filepath]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.4f\t%d\t%.2f\t%.2f\n",
+			filepath, m.Tokens, m.Types, m.TypeTokenRatio, m.Hapax, m.YuleK, m.FleschReadingEase)
+	}
+	return w.Flush()
+}
+
+// lexicalMetrics counts all words of the file and returns it's metrics.
+func lexicalMetrics(filepath string, batchSize int64) (*lexical.Metrics, error) {
+	tk := count.NewUnfiltered(0)
+
+	var sentences int64
+	// NOTE: batches of whole lines don't split words and sentences
+	if err := readLinesAt(filepath, batchSize, nil, func(_ int64, batch []byte) error {
+		processBatch(batch, maxWordLen, tk)
+		atomic.AddInt64(&sentences, analytics.Batch(batch).Sentences)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return lexical.Compute(tk.TopN(tk.Len()), sentences), nil
+}
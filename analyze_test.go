@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lexicalMetrics(t *testing.T) {
+	file := writeTemp(t, "analyze", "The cat saw the dog.\nThe dog ran!\n")
+	defer os.Remove(file)
+
+	// NOTE: small batches would split words and sentences
+	for _, batchSize := range []int64{1, 5, 1024} {
+		m, err := lexicalMetrics(file, batchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, uint64(8), m.Tokens, batchSize)
+		assert.Equal(t, uint64(5), m.Types, batchSize)
+		assert.Equal(t, uint64(3), m.Hapax, batchSize)
+		assert.Equal(t, int64(2), m.Sentences, batchSize)
+		assert.InDelta(t, 0.625, m.TypeTokenRatio, 1e-9, batchSize)
+		assert.InDelta(t, 1250, m.YuleK, 1e-9, batchSize)
+		assert.InDelta(t, 118.175, m.FleschReadingEase, 1e-9, batchSize)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minBatchSize keeps overhead of a batch small compared to it's
+	// processing time.
+	minBatchSize = 64 << 10
+	// maxBatchSize limits memory, every one of GOMAXPROCS workers holds a
+	// batch.
+	maxBatchSize = 16 << 20
+	// batchesPerWorker lets a worker that is done with it's batch pick up the
+	// next one, so fast workers don't wait for the slow ones in the end.
+	batchesPerWorker = 4
+)
+
+// autoBatchSize returns batch size for input of the size processed by the
+// number of workers, so every worker gets batchesPerWorker batches.
+func autoBatchSize(size int64, workers int) int64 {
+	if workers < 1 {
+		workers = 1
+	}
+
+	batchSize := (size + int64(workers*batchesPerWorker) - 1) / int64(workers*batchesPerWorker)
+	switch {
+	case batchSize < minBatchSize:
+		return minBatchSize
+	case batchSize > maxBatchSize:
+		return maxBatchSize
+	default:
+		return batchSize
+	}
+}
+
+// fileBatchSize returns autoBatchSize of the file for GOMAXPROCS workers.
+func fileBatchSize(filepath string) (int64, error) {
+	info, err := os.Stat(filepath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat `%s`: %s", filepath, err)
+	}
+	return autoBatchSize(info.Size(), runtime.GOMAXPROCS(0)), nil
+}
+
+// batchSizeUsage is usage of the -batch-size flag of every command.
+const batchSizeUsage = "read the input in batches of `size`, e.g. 4MiB, picked by file size and number of CPUs by default"
+
+// byteSizeVar defines a byteSize flag with the name and usage in the flags.
+func byteSizeVar(flags *flag.FlagSet, name string, usage string) *byteSize {
+	s := new(byteSize)
+	flags.Var(s, name, usage)
+	return s
+}
+
+// byteSize is a flag of a number of bytes with an optional unit, e.g. "4MiB"
+// or "512kb". Zero is "auto".
+type byteSize int64
+
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	// NOTE: longer suffixes first, so "mib" is not parsed as "b"
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"k", 1 << 10},
+	{"m", 1 << 20},
+	{"g", 1 << 30},
+	{"b", 1},
+}
+
+func (s *byteSize) String() string {
+	if *s == 0 {
+		return "auto"
+	}
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *byteSize) Set(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*s = byteSize(size)
+	return nil
+}
+
+// parseByteSize parses a number of bytes with an optional unit, "auto" is
+// zero.
+func parseByteSize(value string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "auto" {
+		return 0, nil
+	}
+
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, unit = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 || n*float64(unit) >= 1<<62 {
+		return 0, fmt.Errorf("invalid size: `%s`", value)
+	}
+
+	size := int64(n * float64(unit))
+	if size < 1 {
+		return 0, fmt.Errorf("invalid size: `%s`", value)
+	}
+	return size, nil
+}
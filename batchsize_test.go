@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_autoBatchSize(t *testing.T) {
+	testCases := []struct {
+		size     int64
+		workers  int
+		expected int64
+	}{
+		{0, 8, minBatchSize},
+		{1 << 20, 8, minBatchSize},
+		{64 << 20, 8, 2 << 20},
+		{64<<20 + 1, 8, 2<<20 + 1},
+		{64 << 20, 1, maxBatchSize},
+		{64 << 20, 0, maxBatchSize},
+		{2 << 30, 64, 2 << 30 / 256},
+		{100 << 30, 4, maxBatchSize},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d/%d", tc.size, tc.workers), func(t *testing.T) {
+			assert.Equal(t, tc.expected, autoBatchSize(tc.size, tc.workers))
+		})
+	}
+}
+
+func Test_parseByteSize(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected int64
+	}{
+		{"auto", 0},
+		{"1024", 1024},
+		{"4MiB", 4 << 20},
+		{"4 mib", 4 << 20},
+		{"512KiB", 512 << 10},
+		{"1.5GiB", 3 << 29},
+		{"10kb", 10000},
+		{"2MB", 2000000},
+		{"64k", 64 << 10},
+		{"1m", 1 << 20},
+		{"100B", 100},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			size, err := parseByteSize(tc.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, size)
+		})
+	}
+
+	for _, value := range []string{"", "MiB", "-1", "0", "0.1b", "4TiB", "four", "1e30"} {
+		_, err := parseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_fromFile__autoBatchSize(t *testing.T) {
+	file := writeTemp(t, "auto", strings.Repeat("the of ", 1000))
+	defer os.Remove(file)
+
+	tk := count.New(10)
+	stats, err := fromFile(file, 0, tk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(minBatchSize), stats.BatchSize)
+	assert.Equal(t, int64(1), stats.Batches)
+	assert.Equal(t, []count.Element{
+		{Key: "of", Count: 1000},
+		{Key: "the", Count: 1000},
+	}, tk.TopN(10))
+}
+
+// Benchmark_batchSize compares the picked batch size with fixed ones on
+// generated files, run it with -benchtime=5x, generated files are large.
+// autoBatchSizeTolerance is how much slower than the best fixed batch size
+// the picked one can be.
+const autoBatchSizeTolerance = 1.25
+
+func Benchmark_batchSize(b *testing.B) {
+	for _, size := range []int64{1 << 20, 32 << 20, 256 << 20} {
+		size := size
+		b.Run(fmt.Sprintf("file=%dMiB", size>>20), func(b *testing.B) {
+			file := generateFile(b, size)
+			defer os.Remove(file)
+
+			auto := autoBatchSize(size, runtime.GOMAXPROCS(0))
+			batchSizes := []int64{auto, 64 << 10, 1 << 20, 2<<19 - 1, 4 << 20, 16 << 20}
+			perOp := make([]time.Duration, len(batchSizes))
+			for i, batchSize := range batchSizes {
+				i, batchSize := i, batchSize
+				name := fmt.Sprintf("batch=%dKiB", batchSize>>10)
+				if i == 0 {
+					name += "/auto"
+				}
+
+				b.Run(name, func(b *testing.B) {
+					b.SetBytes(size)
+					start := time.Now()
+					for i := 0; i < b.N; i++ {
+						if _, err := fromFile(file, batchSize, count.New(10), nil); err != nil {
+							b.Fatal(err)
+						}
+					}
+					// NOTE: the last run has the largest b.N, so it's kept
+					perOp[i] = time.Since(start) / time.Duration(b.N)
+				})
+			}
+
+			best := -1
+			for i := 1; i < len(perOp); i++ {
+				if perOp[i] > 0 && (best < 0 || perOp[i] < perOp[best]) {
+					best = i
+				}
+			}
+
+			// NOTE: sizes that are filtered out by -bench are not compared
+			if best < 0 || perOp[0] == 0 {
+				return
+			}
+			if float64(perOp[0]) > autoBatchSizeTolerance*float64(perOp[best]) {
+				b.Errorf("auto batch size %dKiB takes %s, %.2fx of the best batch size %dKiB that takes %s",
+					auto>>10, perOp[0], float64(perOp[0])/float64(perOp[best]), batchSizes[best]>>10, perOp[best])
+			}
+		})
+	}
+}
+
+// generateFile writes a file of random words of the size.
+func generateFile(b *testing.B, size int64) string {
+	file, err := ioutil.TempFile("", "batch")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	rnd := rand.New(rand.NewSource(int64(size)))
+	line := []byte{}
+	for written := int64(0); written < size; written += int64(len(line)) {
+		line = line[:0]
+		for len(line) < 80 {
+			line = append(line, randWord(rnd.Intn(10)+1)...)
+			line = append(line, ' ')
+		}
+		line = append(line, '\n')
+
+		if _, err := file.Write(line); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return file.Name()
+}
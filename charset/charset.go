@@ -0,0 +1,176 @@
+// Package charset decodes input of common non utf-8 encodings to utf-8.
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a name of an encoding.
+type Encoding string
+
+// Supported encodings.
+const (
+	UTF8    Encoding = "utf8"
+	UTF16LE Encoding = "utf16le"
+	UTF16BE Encoding = "utf16be"
+	Latin1  Encoding = "latin1"
+	CP1252  Encoding = "cp1252"
+)
+
+// Parse returns the encoding by it's name.
+func Parse(name string) (Encoding, error) {
+	switch Encoding(name) {
+	case UTF8, UTF16LE, UTF16BE, Latin1, CP1252:
+		return Encoding(name), nil
+	default:
+		return "", fmt.Errorf("unknown encoding: `%s`", name)
+	}
+}
+
+var boms = []struct {
+	bom      []byte
+	encoding Encoding
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, UTF8},
+	{[]byte{0xFF, 0xFE}, UTF16LE},
+	{[]byte{0xFE, 0xFF}, UTF16BE},
+}
+
+// Sniff returns the encoding of data by it's byte order mark, or empty
+// string if there is none.
+func Sniff(data []byte) Encoding {
+	for _, b := range boms {
+		if bytes.HasPrefix(data, b.bom) {
+			return b.encoding
+		}
+	}
+	return ""
+}
+
+// BOM returns byte order mark of the encoding, or nil if it has none.
+func BOM(e Encoding) []byte {
+	for _, b := range boms {
+		if b.encoding == e {
+			return b.bom
+		}
+	}
+	return nil
+}
+
+// UnitSize returns size of a code unit of the encoding, data of the encoding
+// can be split only at multiples of it.
+func UnitSize(e Encoding) int {
+	switch e {
+	case UTF16LE, UTF16BE:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Decode appends utf-8 encoded src of the encoding to dst and returns it.
+// Invalid sequences are replaced with utf8.RuneError. Byte order mark is
+// decoded as U+FEFF.
+func Decode(e Encoding, dst []byte, src []byte) []byte {
+	switch e {
+	case UTF16LE:
+		return decodeUTF16(dst, src, func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 })
+	case UTF16BE:
+		return decodeUTF16(dst, src, func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) })
+	case Latin1:
+		return decodeSingleByte(dst, src, nil)
+	case CP1252:
+		return decodeSingleByte(dst, src, &cp1252)
+	default:
+		return append(dst, src...)
+	}
+}
+
+// IsContinuation returns true if data of the encoding can't be split
+// before the unit, e.g. it's a low surrogate of utf-16.
+func IsContinuation(e Encoding, unit []byte) bool {
+	var u uint16
+	switch {
+	case len(unit) < 2:
+		return false
+	case e == UTF16LE:
+		u = uint16(unit[0]) | uint16(unit[1])<<8
+	case e == UTF16BE:
+		u = uint16(unit[0])<<8 | uint16(unit[1])
+	default:
+		return false
+	}
+	return u >= 0xDC00 && u < 0xE000
+}
+
+// IsNewline returns true if the code unit of the encoding is a newline.
+func IsNewline(e Encoding, unit []byte) bool {
+	switch {
+	case len(unit) != UnitSize(e):
+		return false
+	case e == UTF16LE:
+		return unit[0] == '\n' && unit[1] == 0
+	case e == UTF16BE:
+		return unit[0] == 0 && unit[1] == '\n'
+	default:
+		return unit[0] == '\n'
+	}
+}
+
+func decodeUTF16(dst []byte, src []byte, unit func([]byte) uint16) []byte {
+	buf := make([]byte, utf8.UTFMax)
+	for i := 0; i < len(src); i += 2 {
+		if i+1 == len(src) {
+			return appendRune(dst, buf, utf8.RuneError)
+		}
+
+		r := rune(unit(src[i:]))
+		if utf16.IsSurrogate(r) {
+			r2 := utf8.RuneError
+			if i+3 < len(src) {
+				r2 = rune(unit(src[i+2:]))
+			}
+			if decoded := utf16.DecodeRune(r, r2); decoded != utf8.RuneError {
+				r = decoded
+				i += 2
+			} else {
+				r = utf8.RuneError
+			}
+		}
+		dst = appendRune(dst, buf, r)
+	}
+	return dst
+}
+
+func decodeSingleByte(dst []byte, src []byte, high *[32]rune) []byte {
+	buf := make([]byte, utf8.UTFMax)
+	for _, c := range src {
+		switch {
+		case c < utf8.RuneSelf:
+			dst = append(dst, c)
+		case high != nil && c < 0xA0:
+			dst = appendRune(dst, buf, high[c-0x80])
+		default:
+			dst = appendRune(dst, buf, rune(c))
+		}
+	}
+	return dst
+}
+
+func appendRune(dst []byte, buf []byte, r rune) []byte {
+	n := utf8.EncodeRune(buf, r)
+	return append(dst, buf[:n]...)
+}
+
+// cp1252 maps bytes 0x80-0x9F of windows-1252 to runes, the rest is the same
+// as latin1. Undefined bytes are mapped to the control characters of the
+// same code, like browsers do.
+var cp1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
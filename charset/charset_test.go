@@ -0,0 +1,90 @@
+package charset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Decode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		encoding Encoding
+		input    []byte
+		expected string
+	}{
+		{
+			name:     "utf8",
+			encoding: UTF8,
+			input:    []byte("café"),
+			expected: "café",
+		},
+		{
+			name:     "utf16le",
+			encoding: UTF16LE,
+			input:    []byte{0xFF, 0xFE, 'c', 0, 'a', 0, 'f', 0, 0xE9, 0},
+			expected: "\ufeffcafé",
+		},
+		{
+			name:     "utf16be",
+			encoding: UTF16BE,
+			input:    []byte{0, 'h', 0, 'i', 0xD8, 0x3D, 0xDE, 0x00},
+			expected: "hi😀",
+		},
+		{
+			name:     "utf16 invalid",
+			encoding: UTF16LE,
+			input:    []byte{'a', 0, 0x00, 0xDC, 'b', 0, 0x3D, 0xD8, 'c'},
+			expected: "a\ufffdb\ufffd\ufffd",
+		},
+		{
+			name:     "latin1",
+			encoding: Latin1,
+			input:    []byte{'c', 'a', 'f', 0xE9, ' ', 0x92},
+			expected: "café \u0092",
+		},
+		{
+			name:     "cp1252",
+			encoding: CP1252,
+			input:    []byte{'d', 'o', 'n', 0x92, 't', ' ', 0x80, 0x81, 0xE9},
+			expected: "don’t €\u0081é",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(Decode(tc.encoding, nil, tc.input)))
+		})
+	}
+}
+
+func Test_Sniff(t *testing.T) {
+	assert.Equal(t, UTF8, Sniff([]byte{0xEF, 0xBB, 0xBF, 'a'}))
+	assert.Equal(t, UTF16LE, Sniff([]byte{0xFF, 0xFE, 'a', 0}))
+	assert.Equal(t, UTF16BE, Sniff([]byte{0xFE, 0xFF, 0, 'a'}))
+	assert.Equal(t, Encoding(""), Sniff([]byte("a")))
+	assert.Equal(t, Encoding(""), Sniff(nil))
+}
+
+func Test_BOM(t *testing.T) {
+	assert.Equal(t, []byte{0xEF, 0xBB, 0xBF}, BOM(UTF8))
+	assert.Equal(t, []byte{0xFE, 0xFF}, BOM(UTF16BE))
+	assert.Nil(t, BOM(Latin1))
+	assert.Nil(t, BOM(""))
+}
+
+func Test_IsContinuation(t *testing.T) {
+	assert.True(t, IsContinuation(UTF16LE, []byte{0x00, 0xDE}))
+	assert.False(t, IsContinuation(UTF16LE, []byte{0x3D, 0xD8}))
+	assert.True(t, IsContinuation(UTF16BE, []byte{0xDE, 0x00}))
+	assert.False(t, IsContinuation(Latin1, []byte{0xDE, 0x00}))
+	assert.False(t, IsContinuation(UTF16BE, []byte{0xDE}))
+}
+
+func Test_IsNewline(t *testing.T) {
+	assert.True(t, IsNewline(UTF16LE, []byte{'\n', 0x00}))
+	assert.False(t, IsNewline(UTF16LE, []byte{0x00, '\n'}))
+	assert.True(t, IsNewline(UTF16BE, []byte{0x00, '\n'}))
+	assert.True(t, IsNewline(Latin1, []byte{'\n'}))
+	assert.False(t, IsNewline(UTF16LE, []byte{'\n'}))
+}
@@ -9,6 +9,15 @@ import (
 // Words is a map of the most common words in English.
 var Words = hashmap.New(uintptr(len(mostCommonWords)))
 
+// List returns the most common words in English.
+func List() []string {
+	words := make([]string, 0, len(mostCommonWords))
+	for _, word := range mostCommonWords {
+		words = append(words, strings.ToLower(word))
+	}
+	return words
+}
+
 func init() {
 	for _, word := range mostCommonWords {
 		Words.Set(strings.ToLower(word), struct{}{})
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ngalaiko/words/cooccur"
+)
+
+// countCooccurrences prints pairs of words of the file that occur within a
+// window of each other.
+func countCooccurrences(args []string) error {
+	flags := flag.NewFlagSet("cooccur", flag.ExitOnError)
+	window := flags.Int("window", 5, "max distance between words of a pair in words")
+	vocabulary := flags.Bool("vocabulary", false, "count only the most common words")
+	minCount := flags.Uint64("min-count", 1, "skip pairs that occur less than `n` times")
+	format := flags.String("format", "csv", "output format: `csv|json`")
+	batchSize := byteSizeVar(flags, "batch-size", batchSizeUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *window < 1 {
+		return fmt.Errorf("usage: words cooccur [-window=5] [-vocabulary] [-min-count=1] [-format=csv] [-batch-size=auto] file")
+	}
+
+	m, err := cooccurrences(flags.Arg(0), int64(*batchSize), *window, *vocabulary)
+	if err != nil {
+		return err
+	}
+
+	entries := m.Entries(*minCount)
+	switch *format {
+	case "csv":
+		return cooccur.WriteCSV(os.Stdout, entries)
+	case "json":
+		return cooccur.WriteJSON(os.Stdout, entries)
+	default:
+		return fmt.Errorf("unknown format: `%s`", *format)
+	}
+}
+
+// cooccurrences counts co-occurrences of words of the file.
+//
+// Batches are counted independently of each other, so pairs of words on
+// different lines are lost if a batch boundary is between the lines.
+func cooccurrences(filepath string, batchSize int64, window int, vocabulary bool) (*cooccur.Matrix, error) {
+	m := cooccur.NewMatrix()
+	// NOTE: batches are line-aligned, so words are never split by them
+	if err := readLinesAt(filepath, batchSize, nil, func(_ int64, batch []byte) error {
+		w := cooccur.NewWindow(window, vocabulary)
+		tokenize(batch, maxWordLen, func(word []byte, start, end int) {
+			w.Add(string(word))
+		})
+		m.Merge(w.Matrix())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
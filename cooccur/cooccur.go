@@ -0,0 +1,228 @@
+// Package cooccur counts pairs of words that occur close to each other.
+package cooccur
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ngalaiko/words/common"
+)
+
+// Pair is an unordered pair of different words, A is always less than B.
+type Pair struct {
+	A string
+	B string
+}
+
+// NewPair returns a pair of words in order.
+func NewPair(a, b string) Pair {
+	if a > b {
+		a, b = b, a
+	}
+	return Pair{A: a, B: b}
+}
+
+// Matrix is a sparse symmetric co-occurrence matrix. It's safe for concurrent
+// use.
+type Matrix struct {
+	guard  sync.Mutex
+	pairs  map[Pair]uint64
+	words  map[string]uint64
+	tokens uint64
+}
+
+// NewMatrix returns an empty matrix.
+func NewMatrix() *Matrix {
+	return &Matrix{
+		pairs: map[Pair]uint64{},
+		words: map[string]uint64{},
+	}
+}
+
+// Merge adds counts of the other matrix to m.
+func (m *Matrix) Merge(other *Matrix) {
+	other.guard.Lock()
+	defer other.guard.Unlock()
+
+	m.guard.Lock()
+	defer m.guard.Unlock()
+
+	for p, n := range other.pairs {
+		m.pairs[p] += n
+	}
+	for w, n := range other.words {
+		m.words[w] += n
+	}
+	m.tokens += other.tokens
+}
+
+// Count returns number of co-occurrences of the pair.
+func (m *Matrix) Count(p Pair) uint64 {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+
+	return m.pairs[p]
+}
+
+// PMI returns pointwise mutual information of the pair:
+// log2(p(a, b) / (p(a) * p(b))).
+//
+// https://en.wikipedia.org/wiki/Pointwise_mutual_information
+func (m *Matrix) PMI(p Pair) float64 {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+
+	return m.pmi(p, m.pairs[p], m.totalPairs())
+}
+
+func (m *Matrix) pmi(p Pair, count uint64, totalPairs uint64) float64 {
+	if count == 0 || totalPairs == 0 {
+		return math.Inf(-1)
+	}
+
+	pab := float64(count) / float64(totalPairs)
+	pa := float64(m.words[p.A]) / float64(m.tokens)
+	pb := float64(m.words[p.B]) / float64(m.tokens)
+	return math.Log2(pab / (pa * pb))
+}
+
+func (m *Matrix) totalPairs() uint64 {
+	var total uint64
+	for _, n := range m.pairs {
+		total += n
+	}
+	return total
+}
+
+// Entry is a non zero cell of the matrix.
+type Entry struct {
+	A     string  `json:"a"`
+	B     string  `json:"b"`
+	Count uint64  `json:"count"`
+	PMI   float64 `json:"pmi"`
+}
+
+// Entries returns all pairs that occur at least minCount times, the most
+// frequent first.
+func (m *Matrix) Entries(minCount uint64) []Entry {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+
+	total := m.totalPairs()
+
+	entries := make([]Entry, 0, len(m.pairs))
+	for p, n := range m.pairs {
+		if n < minCount {
+			continue
+		}
+		entries = append(entries, Entry{
+			A:     p.A,
+			B:     p.B,
+			Count: n,
+			PMI:   m.pmi(p, n, total),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		if entries[i].A != entries[j].A {
+			return entries[i].A < entries[j].A
+		}
+		return entries[i].B < entries[j].B
+	})
+
+	return entries
+}
+
+// WriteCSV writes entries to w as csv with a header.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"a", "b", "count", "pmi"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := cw.Write([]string{
+			e.A,
+			e.B,
+			strconv.FormatUint(e.Count, 10),
+			strconv.FormatFloat(e.PMI, 'f', 6, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes entries to w as json.
+func WriteJSON(w io.Writer, entries []Entry) error {
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// Window counts co-occurrences of a sequence of words within a window of
+// `size` words. It's not safe for concurrent use, so every batch should use
+// it's own window and merge the result.
+type Window struct {
+	size       int
+	vocabulary bool
+
+	recent []string
+	pos    int
+	matrix *Matrix
+}
+
+// NewWindow returns a new window. If vocabulary is set, only the most common
+// words are counted, other words still occupy positions in the window. A
+// window of a size less than 1 counts nothing.
+func NewWindow(size int, vocabulary bool) *Window {
+	if size < 0 {
+		size = 0
+	}
+	return &Window{
+		size:       size,
+		vocabulary: vocabulary,
+		recent:     make([]string, size),
+		matrix:     NewMatrix(),
+	}
+}
+
+// Add adds next word of the sequence.
+func (w *Window) Add(word string) {
+	if w.size == 0 {
+		return
+	}
+
+	if w.vocabulary {
+		if _, ok := common.Words.GetStringKey(word); !ok {
+			// NOTE: empty word is skipped when counting pairs
+			word = ""
+		}
+	}
+
+	if word != "" {
+		w.matrix.words[word]++
+		w.matrix.tokens++
+
+		for _, prev := range w.recent {
+			if prev == "" || prev == word {
+				continue
+			}
+			w.matrix.pairs[NewPair(prev, word)]++
+		}
+	}
+
+	w.recent[w.pos] = word
+	w.pos = (w.pos + 1) % w.size
+}
+
+// Matrix returns counted co-occurrences.
+func (w *Window) Matrix() *Matrix {
+	return w.matrix
+}
@@ -0,0 +1,64 @@
+package cooccur
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Window(t *testing.T) {
+	w := NewWindow(2, false)
+	for _, word := range strings.Fields("a b c a b") {
+		w.Add(word)
+	}
+	m := w.Matrix()
+
+	assert.Equal(t, uint64(3), m.Count(NewPair("a", "b")))
+	assert.Equal(t, uint64(3), m.Count(NewPair("b", "a")))
+	assert.Equal(t, uint64(2), m.Count(NewPair("b", "c")))
+	assert.Equal(t, uint64(2), m.Count(NewPair("a", "c")))
+
+	// NOTE: p(a, b) = 3/7, p(a) = 2/5, p(b) = 2/5
+	assert.InDelta(t, math.Log2(3.0/7/(0.4*0.4)), m.PMI(NewPair("a", "b")), 1e-9)
+	assert.True(t, math.IsInf(m.PMI(NewPair("a", "d")), -1))
+}
+
+func Test_Window__vocabulary(t *testing.T) {
+	w := NewWindow(1, true)
+	for _, word := range strings.Fields("the cat of the dog of") {
+		w.Add(word)
+	}
+
+	assert.Equal(t, []Entry{
+		{A: "of", B: "the", Count: 1, PMI: w.Matrix().PMI(NewPair("of", "the"))},
+	}, w.Matrix().Entries(0))
+}
+
+func Test_Merge(t *testing.T) {
+	m := NewMatrix()
+	for _, batch := range []string{"a b", "b a c"} {
+		w := NewWindow(5, false)
+		for _, word := range strings.Fields(batch) {
+			w.Add(word)
+		}
+		m.Merge(w.Matrix())
+	}
+
+	entries := m.Entries(2)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "a", entries[0].A)
+		assert.Equal(t, "b", entries[0].B)
+		assert.Equal(t, uint64(2), entries[0].Count)
+	}
+}
+
+func Test_WriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteCSV(buf, []Entry{
+		{A: "a", B: "b", Count: 3, PMI: 1.5},
+	}))
+	assert.Equal(t, "a,b,count,pmi\na,b,3,1.500000\n", buf.String())
+}
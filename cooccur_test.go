@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/cooccur"
+)
+
+func Test_cooccurrences(t *testing.T) {
+	file := writeTemp(t, "cooccur", "Salt and pepper, salt AND pepper.\n")
+	defer os.Remove(file)
+
+	m, err := cooccurrences(file, 1024, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, uint64(2), m.Count(cooccur.NewPair("and", "salt")))
+	assert.Equal(t, uint64(2), m.Count(cooccur.NewPair("and", "pepper")))
+	assert.Equal(t, uint64(1), m.Count(cooccur.NewPair("pepper", "salt")))
+}
+
+func Test_cooccurrences__lines(t *testing.T) {
+	file := writeTemp(t, "cooccur", "salt and pepper\nsalt and pepper\n")
+	defer os.Remove(file)
+
+	// NOTE: words are not split by batches, but pairs across the lines are
+	// lost
+	m, err := cooccurrences(file, 5, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, uint64(2), m.Count(cooccur.NewPair("and", "salt")))
+	assert.Equal(t, uint64(2), m.Count(cooccur.NewPair("and", "pepper")))
+	assert.Equal(t, uint64(0), m.Count(cooccur.NewPair("pepper", "salt")))
+}
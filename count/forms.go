@@ -0,0 +1,53 @@
+package count
+
+import "sync"
+
+// Forms counts surface forms of words, e.g. "US" and "Us" of "us", to report
+// the most common one.
+type Forms struct {
+	guard sync.Mutex
+	words map[string]map[string]uint64
+}
+
+// NewForms returns an empty index of surface forms.
+func NewForms() *Forms {
+	return &Forms{
+		words: map[string]map[string]uint64{},
+	}
+}
+
+// Insert counts the form of the word.
+func (f *Forms) Insert(word, form string) {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	forms, ok := f.words[word]
+	if !ok {
+		forms = map[string]uint64{}
+		f.words[word] = forms
+	}
+	forms[form]++
+}
+
+// Most returns the most common form of the word, ties are broken by the
+// smallest form. If there are no forms of the word, it returns the word.
+func (f *Forms) Most(word string) string {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	most, mostCount := word, uint64(0)
+	for form, count := range f.words[word] {
+		if count > mostCount || count == mostCount && form < most {
+			most, mostCount = form, count
+		}
+	}
+	return most
+}
+
+// Annotate sets Form of every element to the most common form of it's word.
+func (f *Forms) Annotate(ee []Element) []Element {
+	for i := range ee {
+		ee[i].Form = f.Most(ee[i].Key)
+	}
+	return ee
+}
@@ -0,0 +1,29 @@
+package count
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Forms(t *testing.T) {
+	f := NewForms()
+	f.Insert("us", "US")
+	f.Insert("us", "us")
+	f.Insert("us", "US")
+	f.Insert("the", "The")
+	f.Insert("the", "the")
+
+	assert.Equal(t, "US", f.Most("us"))
+	// NOTE: ties are broken by the smallest form
+	assert.Equal(t, "The", f.Most("the"))
+	assert.Equal(t, "of", f.Most("of"))
+
+	assert.Equal(t, []Element{
+		{Key: "us", Count: 3, Form: "US"},
+		{Key: "of", Count: 1, Form: "of"},
+	}, f.Annotate([]Element{
+		{Key: "us", Count: 3},
+		{Key: "of", Count: 1},
+	}))
+}
@@ -0,0 +1,167 @@
+package count
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Position is a position of a word in the input.
+type Position struct {
+	// Offset is a byte offset of the word.
+	Offset int64 `json:"offset"`
+	// Line is 1-based line number of the word.
+	Line int64 `json:"line"`
+	// Column is 1-based byte offset of the word in it's line.
+	Column int64 `json:"column"`
+}
+
+// Hint is a line of a position relative to it's batch, when batches are
+// processed out of order. Resolve turns hints into lines and columns.
+type Hint struct {
+	// Newlines is a number of newlines before the position in it's batch.
+	Newlines int64
+	// LastNewline is an offset of the last of them, or -1 if there are
+	// none.
+	LastNewline int64
+}
+
+// position is a recorded position, line and column of it are zero until
+// it's resolved.
+type position struct {
+	Position
+	hint Hint
+}
+
+// Positions records the first and a uniform sample of all positions of every
+// word. Words can be inserted in any order, "first" means with the smallest
+// offset.
+type Positions struct {
+	first  int
+	sample int
+
+	guard sync.Mutex
+	rand  *rand.Rand
+	words map[string]*wordPositions
+}
+
+// WordPositions are recorded positions of a word.
+type WordPositions struct {
+	// First are positions with the smallest offsets, ordered by offset.
+	First []Position `json:"first,omitempty"`
+	// Sample is a uniform sample of all positions, ordered by offset.
+	Sample []Position `json:"sample,omitempty"`
+	// Seen is a number of all positions of the word.
+	Seen uint64 `json:"seen"`
+}
+
+type wordPositions struct {
+	first  []position
+	sample []position
+	seen   uint64
+}
+
+// NewPositions returns an index that records `first` first positions and a
+// sample of `sample` positions of every word. Seed makes sampling
+// deterministic.
+func NewPositions(first, sample int, seed int64) *Positions {
+	return &Positions{
+		first:  first,
+		sample: sample,
+		rand:   rand.New(rand.NewSource(seed)),
+		words:  map[string]*wordPositions{},
+	}
+}
+
+// Insert records a position of the word at offset. Line and column are
+// unknown until Resolve is called with the hint.
+func (p *Positions) Insert(word string, offset int64, hint Hint) {
+	pos := position{
+		Position: Position{Offset: offset},
+		hint:     hint,
+	}
+
+	p.guard.Lock()
+	defer p.guard.Unlock()
+
+	wp, ok := p.words[word]
+	if !ok {
+		wp = &wordPositions{}
+		p.words[word] = wp
+	}
+	wp.seen++
+
+	if p.first > 0 {
+		i := sort.Search(len(wp.first), func(i int) bool {
+			return wp.first[i].Offset > pos.Offset
+		})
+		switch {
+		case len(wp.first) < p.first:
+			wp.first = append(wp.first, position{})
+			copy(wp.first[i+1:], wp.first[i:])
+			wp.first[i] = pos
+		case i < len(wp.first):
+			copy(wp.first[i+1:], wp.first[i:len(wp.first)-1])
+			wp.first[i] = pos
+		}
+	}
+
+	if p.sample > 0 {
+		// NOTE: reservoir sampling, the order of insertion doesn't matter
+		// https://en.wikipedia.org/wiki/Reservoir_sampling
+		if len(wp.sample) < p.sample {
+			wp.sample = append(wp.sample, pos)
+		} else if j := p.rand.Int63n(int64(wp.seen)); j < int64(p.sample) {
+			wp.sample[j] = pos
+		}
+	}
+}
+
+// Get returns recorded positions of the word.
+func (p *Positions) Get(word string) (*WordPositions, bool) {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+
+	wp, ok := p.words[word]
+	if !ok {
+		return nil, false
+	}
+
+	res := &WordPositions{
+		First:  publicPositions(wp.first),
+		Sample: publicPositions(wp.sample),
+		Seen:   wp.seen,
+	}
+	sort.Slice(res.Sample, func(i, j int) bool {
+		return res.Sample[i].Offset < res.Sample[j].Offset
+	})
+	return res, true
+}
+
+// Resolve sets line and column of every recorded position to fn of it's
+// offset and hint. It's useful when line and column can be computed only
+// after all words are inserted.
+func (p *Positions) Resolve(fn func(offset int64, hint Hint) (line int64, column int64)) {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+
+	for _, wp := range p.words {
+		for _, pp := range [][]position{wp.first, wp.sample} {
+			for i := range pp {
+				pp[i].Line, pp[i].Column = fn(pp[i].Offset, pp[i].hint)
+			}
+		}
+	}
+}
+
+func publicPositions(pp []position) []Position {
+	if pp == nil {
+		return nil
+	}
+
+	res := make([]Position, 0, len(pp))
+	for _, pos := range pp {
+		res = append(res, pos.Position)
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package count
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Positions__first(t *testing.T) {
+	p := NewPositions(2, 0, 0)
+	for _, offset := range []int64{30, 10, 40, 20} {
+		p.Insert("the", offset, Hint{})
+	}
+
+	wp, ok := p.Get("the")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, uint64(4), wp.Seen)
+	assert.Equal(t, []Position{{Offset: 10}, {Offset: 20}}, wp.First)
+	assert.Empty(t, wp.Sample)
+
+	_, ok = p.Get("of")
+	assert.False(t, ok)
+}
+
+func Test_Positions__sample(t *testing.T) {
+	p := NewPositions(0, 3, 42)
+	for offset := int64(100); offset > 0; offset-- {
+		p.Insert("the", offset, Hint{})
+	}
+
+	wp, _ := p.Get("the")
+	assert.Equal(t, uint64(100), wp.Seen)
+	assert.Len(t, wp.Sample, 3)
+	for i := 1; i < len(wp.Sample); i++ {
+		assert.True(t, wp.Sample[i-1].Offset < wp.Sample[i].Offset)
+	}
+}
+
+func Test_Positions__resolve(t *testing.T) {
+	p := NewPositions(1, 1, 0)
+	p.Insert("the", 5, Hint{Newlines: 1, LastNewline: 3})
+
+	// NOTE: line and column are unknown until resolved
+	wp, _ := p.Get("the")
+	assert.Equal(t, []Position{{Offset: 5}}, wp.First)
+
+	p.Resolve(func(offset int64, hint Hint) (int64, int64) {
+		return hint.Newlines + 1, offset - hint.LastNewline
+	})
+
+	wp, _ = p.Get("the")
+	assert.Equal(t, []Position{{Offset: 5, Line: 2, Column: 2}}, wp.First)
+	assert.Equal(t, []Position{{Offset: 5, Line: 2, Column: 2}}, wp.Sample)
+}
@@ -1,6 +1,7 @@
 package count
 
 import (
+	"sync"
 	"sync/atomic"
 
 	"github.com/cornelk/hashmap"
@@ -8,20 +9,30 @@ import (
 )
 
 type Stream struct {
-	n int
+	n        int
+	filtered bool
 
+	// NOTE: concurrent inserts of new words race inside of the hashmap, so
+	// they are serialized, counters of known words are updated without
+	// locking
+	guard        sync.RWMutex
 	frequencyMap *hashmap.HashMap
 }
 
 type Element struct {
-	Key   string
-	Count uint64
+	Key   string `json:"word"`
+	Count uint64 `json:"count"`
+	// Form is the most common surface form of the word, if forms are
+	// tracked, see Forms.
+	Form string `json:"form,omitempty"`
 }
 
 func New(n int) *Stream {
 	return &Stream{
-		n: n,
+		n:        n,
+		filtered: true,
 		// NOTE: Implementation of a map with CAS acces to avoid locking
+		// on updates of known words
 		// https://en.wikipedia.org/wiki/Compare-and-swap
 		frequencyMap: hashmap.New(
 			uintptr(common.Words.Len()),
@@ -29,17 +40,24 @@ func New(n int) *Stream {
 	}
 }
 
+// NewUnfiltered returns a stream that counts all words, not only the most
+// common ones.
+func NewUnfiltered(n int) *Stream {
+	return &Stream{
+		n:            n,
+		frequencyMap: hashmap.New(1 << 10),
+	}
+}
+
 func (c *Stream) Keys() []Element {
 	// NOTE: 2^25 = 33554432
 	// assume it's larger then a number of occurrences for the most frequent word
 	// and use bucket sort to find the most frequent comments
 	freq := make([]*string, 2<<25)
 
-	for kv := range c.frequencyMap.Iter() {
-		wCopy := kv.Key.(string)
-		counter := kv.Value.(*int64)
-		freq[*counter] = &wCopy
-	}
+	c.each(func(word string, count int64) {
+		freq[count] = &word
+	})
 
 	res := make([]Element, 0, 10)
 	for i := uint64(len(freq) - 1); i > 0 && len(res) < c.n; i-- {
@@ -55,16 +73,98 @@ func (c *Stream) Keys() []Element {
 	return res
 }
 
-func (c *Stream) Insert(word string) {
-	if _, ok := common.Words.GetStringKey(word); !ok {
-		// NOTE: Assume that 14m words pretty much represent English language and
-		// count only 100 most common words in the English language.
-		// https://en.wikipedia.org/wiki/Law_of_large_numbers
-		return
+// Len returns number of distinct counted words.
+func (c *Stream) Len() int {
+	c.guard.RLock()
+	defer c.guard.RUnlock()
+
+	return c.frequencyMap.Len()
+}
+
+// TopN returns n most frequent words, most frequent first.
+// Unlike Keys, it doesn't allocate buckets for every possible count, so it's
+// cheap to call it often.
+func (c *Stream) TopN(n int) []Element {
+	res := make([]Element, 0, c.Len())
+	c.each(func(word string, count int64) {
+		res = append(res, Element{
+			Key:   word,
+			Count: uint64(count),
+		})
+	})
+
+	sortElements(res)
+
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// Filtered returns true if the stream counts only the most common words.
+func (c *Stream) Filtered() bool {
+	return c.filtered
+}
+
+// Insert counts the word, returns false if the word was filtered out.
+func (c *Stream) Insert(word string) bool {
+	return c.InsertAs(word, word)
+}
+
+// InsertAs counts the word, but filters it by the key, e.g. by a lowercase
+// form of a case-sensitive word. Returns false if the word was filtered out.
+func (c *Stream) InsertAs(word string, key string) bool {
+	if c.filtered {
+		if _, ok := common.Words.GetStringKey(key); !ok {
+			// NOTE: Assume that 14m words pretty much represent English language and
+			// count only 100 most common words in the English language.
+			// https://en.wikipedia.org/wiki/Law_of_large_numbers
+			return false
+		}
+	}
+
+	c.add(word, 1)
+	return true
+}
+
+// Merge adds counts of the other stream to the stream.
+func (c *Stream) Merge(other *Stream) {
+	counts := map[string]int64{}
+	other.each(func(word string, count int64) {
+		counts[word] = count
+	})
+
+	for word, count := range counts {
+		c.add(word, count)
 	}
+}
 
-	var i int64
-	actual, _ := c.frequencyMap.GetOrInsert(word, &i)
-	counter := (actual).(*int64)
-	atomic.AddInt64(counter, 1)
+func (c *Stream) add(word string, n int64) {
+	atomic.AddInt64(c.counter(word), n)
+}
+
+// counter returns a counter of the word, it's inserted if it's missing.
+func (c *Stream) counter(word string) *int64 {
+	c.guard.RLock()
+	actual, ok := c.frequencyMap.GetStringKey(word)
+	c.guard.RUnlock()
+	if ok {
+		return actual.(*int64)
+	}
+
+	c.guard.Lock()
+	defer c.guard.Unlock()
+
+	actual, _ = c.frequencyMap.GetOrInsert(word, new(int64))
+	return actual.(*int64)
+}
+
+// each calls fn for every counted word and it's count.
+func (c *Stream) each(fn func(word string, count int64)) {
+	c.guard.RLock()
+	defer c.guard.RUnlock()
+
+	for kv := range c.frequencyMap.Iter() {
+		fn(kv.Key.(string), atomic.LoadInt64(kv.Value.(*int64)))
+	}
 }
@@ -0,0 +1,30 @@
+package count
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Stream__concurrentInsert(t *testing.T) {
+	s := NewUnfiltered(10)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Insert("the")
+				s.Insert("of")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 800},
+		{Key: "the", Count: 800},
+	}, s.TopN(10))
+}
@@ -0,0 +1,148 @@
+package count
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ngalaiko/words/common"
+)
+
+// Clock returns current time, it's injectable to drive Window in tests.
+type Clock func() time.Time
+
+// Window counts words over a sliding time window.
+//
+// The window is split into buckets of `slide` duration each, buckets are
+// stored in a ring and reused once they fall out of the window.
+type Window struct {
+	slide time.Duration
+	now   Clock
+
+	guard   sync.Mutex
+	buckets []*bucket
+}
+
+type bucket struct {
+	// epoch is a number of the slide interval since unix epoch
+	epoch  int64
+	counts map[string]uint64
+}
+
+// NewWindow returns a counter for the last `window` duration, that moves
+// forward every `slide` duration. Window must be a multiple of slide. If now
+// is nil, time.Now is used.
+func NewWindow(window, slide time.Duration, now Clock) (*Window, error) {
+	switch {
+	case window <= 0:
+		return nil, fmt.Errorf("window must be positive, got %s", window)
+	case slide <= 0:
+		return nil, fmt.Errorf("slide must be positive, got %s", slide)
+	case window%slide != 0:
+		return nil, fmt.Errorf("window %s is not a multiple of slide %s", window, slide)
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	size := int(window / slide)
+
+	buckets := make([]*bucket, size)
+	for i := range buckets {
+		buckets[i] = &bucket{
+			epoch:  -1,
+			counts: map[string]uint64{},
+		}
+	}
+
+	return &Window{
+		slide:   slide,
+		now:     now,
+		buckets: buckets,
+	}, nil
+}
+
+func (w *Window) epoch() int64 {
+	// NOTE: round down, so that time before unix epoch is not in the same
+	// interval as time after it
+	nanos, slide := w.now().UnixNano(), int64(w.slide)
+	epoch := nanos / slide
+	if nanos%slide < 0 {
+		epoch--
+	}
+	return epoch
+}
+
+// Insert counts the word in the current interval.
+func (w *Window) Insert(word string) {
+	if _, ok := common.Words.GetStringKey(word); !ok {
+		return
+	}
+
+	epoch := w.epoch()
+
+	w.guard.Lock()
+	defer w.guard.Unlock()
+
+	index := epoch % int64(len(w.buckets))
+	if index < 0 {
+		index += int64(len(w.buckets))
+	}
+
+	b := w.buckets[index]
+	if b.epoch != epoch {
+		// NOTE: bucket is expired, reuse it for the current interval
+		b.epoch = epoch
+		b.counts = map[string]uint64{}
+	}
+	b.counts[word]++
+}
+
+// TopN returns n most frequent words within the window, most frequent first.
+func (w *Window) TopN(n int) []Element {
+	epoch := w.epoch()
+	oldest := epoch - int64(len(w.buckets)) + 1
+
+	total := map[string]uint64{}
+
+	w.guard.Lock()
+	for _, b := range w.buckets {
+		if b.epoch < oldest || b.epoch > epoch {
+			continue
+		}
+		for word, count := range b.counts {
+			total[word] += count
+		}
+	}
+	w.guard.Unlock()
+
+	res := make([]Element, 0, len(total))
+	for word, count := range total {
+		res = append(res, Element{
+			Key:   word,
+			Count: count,
+		})
+	}
+
+	sortElements(res)
+
+	switch {
+	case n < 0:
+		return res[:0]
+	case len(res) > n:
+		return res[:n]
+	default:
+		return res
+	}
+}
+
+// sortElements sorts elements by count descending, ties are sorted by key.
+func sortElements(ee []Element) {
+	sort.Slice(ee, func(i, j int) bool {
+		if ee[i].Count != ee[j].Count {
+			return ee[i].Count > ee[j].Count
+		}
+		return ee[i].Key < ee[j].Key
+	})
+}
@@ -0,0 +1,118 @@
+package count
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func Test_Window(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	w, err := NewWindow(5*time.Minute, time.Minute, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Insert("the")
+	w.Insert("the")
+	w.Insert("of")
+	w.Insert("notaword")
+
+	assert.Equal(t, []Element{
+		{Key: "the", Count: 2},
+		{Key: "of", Count: 1},
+	}, w.TopN(10))
+
+	clock.Add(3 * time.Minute)
+	w.Insert("of")
+	w.Insert("of")
+	w.Insert("and")
+
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 3},
+		{Key: "the", Count: 2},
+	}, w.TopN(2))
+
+	// NOTE: the first interval is out of the window now
+	clock.Add(2 * time.Minute)
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 2},
+		{Key: "and", Count: 1},
+	}, w.TopN(10))
+
+	clock.Add(10 * time.Minute)
+	assert.Empty(t, w.TopN(10))
+}
+
+func Test_Window__reuseBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	w, err := NewWindow(2*time.Second, time.Second, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Insert("the")
+	clock.Add(2 * time.Second)
+	w.Insert("of")
+
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 1},
+	}, w.TopN(10))
+}
+
+func Test_Window__beforeEpoch(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(-10, 0)}
+	w, err := NewWindow(3*time.Second, time.Second, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Insert("the")
+	clock.Add(time.Second)
+	w.Insert("of")
+
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 1},
+		{Key: "the", Count: 1},
+	}, w.TopN(10))
+	assert.Empty(t, w.TopN(-1))
+
+	clock.Add(2 * time.Second)
+	assert.Equal(t, []Element{
+		{Key: "of", Count: 1},
+	}, w.TopN(10))
+}
+
+func Test_NewWindow__errors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		window, slide time.Duration
+	}{
+		{name: "zero window", window: 0, slide: time.Second},
+		{name: "negative window", window: -time.Minute, slide: time.Second},
+		{name: "zero slide", window: time.Minute, slide: 0},
+		{name: "negative slide", window: time.Minute, slide: -time.Second},
+		{name: "not a multiple", window: time.Minute, slide: 7 * time.Second},
+		{name: "slide longer than window", window: time.Second, slide: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewWindow(tc.window, tc.slide, nil)
+			assert.Error(t, err)
+		})
+	}
+}
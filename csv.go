@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ngalaiko/words/charset"
+)
+
+// csvColumn selects a column of csv or tsv input to count words in.
+type csvColumn struct {
+	comma rune
+	// name is a name of the column, if it's set the first record is a
+	// header.
+	name string
+	// index is 0-based index of the column.
+	index int
+}
+
+// newCSVColumn returns a column selected by it's name or index.
+func newCSVColumn(column string, comma rune) *csvColumn {
+	if index, err := strconv.Atoi(column); err == nil {
+		return &csvColumn{
+			comma: comma,
+			index: index,
+		}
+	}
+	return &csvColumn{
+		comma: comma,
+		name:  column,
+	}
+}
+
+// readBatchesAt is like the readBatchesAt function, but every batch consists
+// of whole records and fn gets values of the column separated by newlines instead of
+// the batch. Offset is still offset of the batch in the file.
+func (c *csvColumn) readBatchesAt(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+	defer file.Close()
+
+	// NOTE: newlines can be quoted, so it's impossible to find a start of
+	// a record reading the file from the middle. Record boundaries are
+	// found sequentially, it's much faster than parsing.
+	boundaries, headerEnd, err := recordBoundaries(file, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+
+	index := c.index
+	start := int64(0)
+	if c.name != "" {
+		if index, err = c.headerIndex(file, headerEnd); err != nil {
+			return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+		}
+		start = headerEnd
+	}
+
+	return readSplitBatchesAt(filepath, batchSize, p, splitAt(boundaries, start), func(offset int64, batch []byte) error {
+		values, err := c.values(batch, index)
+		if err != nil {
+			return fmt.Errorf("failed to parse `%s` at offset %d: %s", filepath, offset, err)
+		}
+		return fn(offset, values)
+	})
+}
+
+// headerIndex returns index of the column in the header that ends at
+// headerEnd.
+func (c *csvColumn) headerIndex(r io.ReaderAt, headerEnd int64) (int, error) {
+	header := make([]byte, headerEnd)
+	if _, err := r.ReadAt(header, 0); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	// NOTE: byte order mark is not a part of the first column name
+	reader := c.reader(bytes.TrimPrefix(header, charset.BOM(charset.UTF8)))
+	record, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse header: %s", err)
+	}
+
+	for i, name := range record {
+		if name == c.name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("column `%s` not found", c.name)
+}
+
+// values returns values of the column at index in all records of the batch,
+// separated by newlines. Records without the column are skipped.
+func (c *csvColumn) values(batch []byte, index int) ([]byte, error) {
+	reader := c.reader(batch)
+	reader.ReuseRecord = true
+
+	values := make([]byte, 0, len(batch))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if index >= len(record) {
+			continue
+		}
+		values = append(values, record[index]...)
+		values = append(values, '\n')
+	}
+}
+
+func (c *csvColumn) reader(data []byte) *csv.Reader {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = c.comma
+	// NOTE: don't fail on records with a different number of fields
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// recordBoundaries returns the first record start after every multiple of
+// batchSize and the end of the first record. Newlines in quotes don't end
+// records.
+func recordBoundaries(r io.Reader, batchSize int64) ([]int64, int64, error) {
+	boundaries := []int64{}
+	headerEnd := int64(-1)
+	next := batchSize
+	inQuotes := false
+
+	pos := int64(0)
+	buff := make([]byte, 64<<10)
+	for {
+		n, err := r.Read(buff)
+		for _, c := range buff[:n] {
+			pos++
+			switch {
+			case c == '"':
+				// NOTE: escaped quote is two quotes, so it doesn't
+				// change the state
+				inQuotes = !inQuotes
+			case c == '\n' && !inQuotes:
+				if headerEnd == -1 {
+					headerEnd = pos
+				}
+				if pos >= next {
+					boundaries = append(boundaries, pos)
+					next = (pos/batchSize + 1) * batchSize
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if headerEnd == -1 {
+		headerEnd = pos
+	}
+
+	return boundaries, headerEnd, nil
+}
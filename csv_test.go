@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+const testCSV = `id,text,author
+1,"the cat
+and the dog",of
+2,"she said ""the end""",the
+3,"multiple
+
+lines, of the text",and
+4,plain text of
+5
+`
+
+func Test_fromFile__csv(t *testing.T) {
+	file := writeTemp(t, "csv", testCSV)
+	defer os.Remove(file)
+
+	// NOTE: small batches start inside of quoted newlines
+	for _, batchSize := range []int64{1, 7, 16, 1024} {
+		tk := count.New(10)
+		if _, err := fromFile(file, batchSize, tk, &options{
+			csv: newCSVColumn("text", ','),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "the", Count: 4},
+			{Key: "of", Count: 2},
+			{Key: "and", Count: 1},
+			{Key: "she", Count: 1},
+		}, tk.TopN(10), batchSize)
+	}
+}
+
+func Test_fromFile__csvIndex(t *testing.T) {
+	file := writeTemp(t, "csv", testCSV)
+	defer os.Remove(file)
+
+	tk := count.NewUnfiltered(10)
+	if _, err := fromFile(file, 16, tk, &options{
+		csv: newCSVColumn("2", ','),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: there is no header, so "author" is a value too
+	assert.Equal(t, []count.Element{
+		{Key: "and", Count: 1},
+		{Key: "author", Count: 1},
+		{Key: "of", Count: 1},
+		{Key: "the", Count: 1},
+	}, tk.TopN(10))
+}
+
+func Test_fromFile__tsv(t *testing.T) {
+	file := writeTemp(t, "tsv", "text\tid\nthe cat\t1\n\"of\tthe\"\t2\n")
+	defer os.Remove(file)
+
+	tk := count.New(10)
+	if _, err := fromFile(file, 4, tk, &options{
+		csv: newCSVColumn("text", '\t'),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []count.Element{
+		{Key: "the", Count: 2},
+		{Key: "of", Count: 1},
+	}, tk.TopN(10))
+}
+
+func Test_fromFile__csvErrors(t *testing.T) {
+	file := writeTemp(t, "csv", testCSV)
+	defer os.Remove(file)
+
+	_, err := fromFile(file, 16, count.New(10), &options{
+		csv: newCSVColumn("missing", ','),
+	})
+	assert.EqualError(t, err, "failed to read `"+file+"`: column `missing` not found")
+
+	malformed := writeTemp(t, "csv", "text\nthe \"cat\"\n")
+	defer os.Remove(malformed)
+
+	_, err = fromFile(malformed, 16, count.New(10), &options{
+		csv: newCSVColumn("text", ','),
+	})
+	assert.Error(t, err)
+}
+
+func Test_recordBoundaries(t *testing.T) {
+	boundaries, headerEnd, err := recordBoundaries(strings.NewReader(testCSV), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(15), headerEnd)
+	assert.Equal(t, []int64{15, 42, 71, 108, 124}, boundaries)
+}
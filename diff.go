@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/keyness"
+)
+
+// diff prints words that are unusually frequent in one file compared to
+// another.
+func diff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	n := flags.Int("n", 10, "number of keywords to print for each file")
+	method := flags.String("method", string(keyness.LogLikelihood), "rank keywords by `g2|chi2|diff`")
+	all := flags.Bool("all", false, "count all words, not only the most common ones")
+	batchSize := byteSizeVar(flags, "batch-size", batchSizeUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: words diff [-n=10] [-method=g2] [-all] [-batch-size=auto] a.txt b.txt")
+	}
+
+	m, err := keyness.ParseMethod(*method)
+	if err != nil {
+		return err
+	}
+
+	a, err := countFile(flags.Arg(0), int64(*batchSize), *all)
+	if err != nil {
+		return err
+	}
+
+	b, err := countFile(flags.Arg(1), int64(*batchSize), *all)
+	if err != nil {
+		return err
+	}
+
+	kk := keyness.Compare(a.TopN(a.Len()), b.TopN(b.Len()), m)
+
+	positive := make([]keyness.Keyword, 0, *n)
+	for i := 0; i < len(kk) && len(positive) < *n && kk[i].Score(m) > 0; i++ {
+		positive = append(positive, kk[i])
+	}
+
+	negative := make([]keyness.Keyword, 0, *n)
+	for i := len(kk) - 1; i >= 0 && len(negative) < *n && kk[i].Score(m) < 0; i-- {
+		negative = append(negative, kk[i])
+	}
+
+	fmt.Printf("more frequent in %s:\n", flags.Arg(0))
+	if err := printKeywords(os.Stdout, positive); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nmore frequent in %s:\n", flags.Arg(1))
+	return printKeywords(os.Stdout, negative)
+}
+
+// countFile counts words of the file in batches of batchSize, if all is set it
+// counts all words, otherwise only the most common ones.
+func countFile(filepath string, batchSize int64, all bool) (*count.Stream, error) {
+	if all {
+		return countAll(filepath, batchSize)
+	}
+
+	tk := count.New(0)
+	if _, err := fromFile(filepath, batchSize, tk, nil); err != nil {
+		return nil, err
+	}
+	return tk, nil
+}
+
+func printKeywords(w io.Writer, kk []keyness.Keyword) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "word\ta\tb\tg2\tchi2\tdiff%")
+	for _, k := range kk {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%.2f\t%s\n",
+			k.Word, k.A, k.B, math.Abs(k.LogLikelihood), math.Abs(k.ChiSquared), formatPercent(k.PercentDiff))
+	}
+	return tw.Flush()
+}
+
+func formatPercent(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+inf"
+	}
+	return fmt.Sprintf("%+.1f", v)
+}
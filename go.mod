@@ -1,9 +1,15 @@
 module github.com/ngalaiko/words
 
-go 1.12
+go 1.19
 
 require (
 	github.com/cornelk/hashmap v1.0.0
 	github.com/stretchr/testify v1.3.0
-	golang.org/x/sync v0.0.0-20190423024810-112230192c58
+	golang.org/x/sync v0.2.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dchest/siphash v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
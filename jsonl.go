@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ngalaiko/words/charset"
+)
+
+// jsonlField selects string values of json lines input to count words in.
+type jsonlField struct {
+	// path is a dotted path of the field, e.g. "user.name". Arrays are
+	// traversed, unless a path element is an index of an array element.
+	path []string
+}
+
+func newJSONLField(path string) *jsonlField {
+	return &jsonlField{
+		path: strings.Split(path, "."),
+	}
+}
+
+// readBatchesAt is like readLinesAt, but fn gets string values of the field
+// in all lines of the batch separated by newlines instead of the batch.
+// Malformed lines are skipped, it returns a number of them.
+func (f *jsonlField) readBatchesAt(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) (int64, error) {
+	malformed := int64(0)
+	err := readLinesAt(filepath, batchSize, p, func(offset int64, batch []byte) error {
+		if offset == 0 {
+			// NOTE: byte order mark is not a part of the first line
+			batch = bytes.TrimPrefix(batch, charset.BOM(charset.UTF8))
+		}
+		values, batchMalformed := f.values(batch)
+		atomic.AddInt64(&malformed, batchMalformed)
+		return fn(offset, values)
+	})
+	return atomic.LoadInt64(&malformed), err
+}
+
+// values returns string values of the field in all lines of the batch,
+// separated by newlines, and a number of malformed lines.
+func (f *jsonlField) values(batch []byte) ([]byte, int64) {
+	values := make([]byte, 0, len(batch)/2)
+	malformed := int64(0)
+	for len(batch) > 0 {
+		line := batch
+		if i := bytes.IndexByte(batch, '\n'); i != -1 {
+			line, batch = batch[:i], batch[i+1:]
+		} else {
+			batch = nil
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(line, &v); err != nil {
+			malformed++
+			continue
+		}
+
+		collect(v, f.path, func(value string) {
+			values = append(values, value...)
+			values = append(values, '\n')
+		})
+	}
+	return values, malformed
+}
+
+// collect calls fn for every string at the path of v.
+func collect(v interface{}, path []string, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		if len(path) == 0 {
+			fn(v)
+		}
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return
+		}
+		if value, ok := v[path[0]]; ok {
+			collect(value, path[1:], fn)
+		}
+	case []interface{}:
+		if len(path) > 0 {
+			if i, err := strconv.Atoi(path[0]); err == nil {
+				if i >= 0 && i < len(v) {
+					collect(v[i], path[1:], fn)
+				}
+				return
+			}
+		}
+		for _, value := range v {
+			collect(value, path, fn)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_jsonlField__values(t *testing.T) {
+	testCases := []struct {
+		path      string
+		input     string
+		expected  string
+		malformed int64
+	}{
+		{
+			path:     "message",
+			input:    `{"message": "the cat", "level": "info"}` + "\n" + `{"level": "debug"}`,
+			expected: "the cat\n",
+		},
+		{
+			path:     "user.name",
+			input:    `{"user": {"name": "Alice"}}` + "\n" + `{"user": "bob"}`,
+			expected: "Alice\n",
+		},
+		{
+			path:     "tags",
+			input:    `{"tags": ["a", "b", 1, ["c"]]}`,
+			expected: "a\nb\nc\n",
+		},
+		{
+			path:     "items.text",
+			input:    `{"items": [{"text": "one"}, {"id": 2}, {"text": "three"}]}`,
+			expected: "one\nthree\n",
+		},
+		{
+			path:     "items.1.text",
+			input:    `{"items": [{"text": "one"}, {"text": "two"}]}`,
+			expected: "two\n",
+		},
+		{
+			path:     "count",
+			input:    `{"count": 1}` + "\n" + `{"count": {"nested": "x"}}`,
+			expected: "",
+		},
+		{
+			path:      "message",
+			input:     "{\"message\": \"ok\"}\n\n{\"message\": \nnot json\n",
+			expected:  "ok\n",
+			malformed: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			values, malformed := newJSONLField(tc.path).values([]byte(tc.input))
+			assert.Equal(t, tc.expected, string(values))
+			assert.Equal(t, tc.malformed, malformed)
+		})
+	}
+}
+
+func Test_fromFile__jsonl(t *testing.T) {
+	file := writeTemp(t, "jsonl", `{"level": "info", "message": "the cat of the dog"}
+{"level": "error", "message": "the end"}
+{"level": "info", "message": 
+{"level": "info", "message": "that is all"}
+`)
+	defer os.Remove(file)
+
+	for _, batchSize := range []int64{8, 1024} {
+		tk := count.New(10)
+		stats, err := fromFile(file, batchSize, tk, &options{
+			jsonl: newJSONLField("message"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "the", Count: 3},
+			{Key: "all", Count: 1},
+			{Key: "of", Count: 1},
+			{Key: "that", Count: 1},
+		}, tk.TopN(10), batchSize)
+		assert.Equal(t, int64(1), stats.MalformedLines, batchSize)
+	}
+}
@@ -0,0 +1,157 @@
+// Package keyness finds words that are unusually frequent in one corpus
+// compared to another.
+//
+// http://ucrel.lancs.ac.uk/llwizard.html
+package keyness
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ngalaiko/words/count"
+)
+
+// Keyword is a word with it's counts in both corpora and keyness scores.
+// Scores are positive if the word is more frequent in the first corpus and
+// negative otherwise.
+type Keyword struct {
+	Word string
+	A    uint64
+	B    uint64
+	// LogLikelihood is G² statistic.
+	LogLikelihood float64
+	// ChiSquared is Pearson's chi-squared statistic.
+	ChiSquared float64
+	// PercentDiff is a difference of relative frequencies in percents of the
+	// relative frequency in the second corpus. It's +Inf if the word doesn't
+	// occur in the second corpus.
+	PercentDiff float64
+}
+
+// Method defines how keywords are ranked.
+type Method string
+
+// Supported methods.
+const (
+	LogLikelihood Method = "g2"
+	ChiSquared    Method = "chi2"
+	PercentDiff   Method = "diff"
+)
+
+// Score returns score of the keyword by the method.
+func (k *Keyword) Score(m Method) float64 {
+	switch m {
+	case ChiSquared:
+		return k.ChiSquared
+	case PercentDiff:
+		return k.PercentDiff
+	default:
+		return k.LogLikelihood
+	}
+}
+
+// ParseMethod returns a method by it's name.
+func ParseMethod(name string) (Method, error) {
+	switch m := Method(name); m {
+	case LogLikelihood, ChiSquared, PercentDiff:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown keyness method: `%s`", name)
+	}
+}
+
+// Compare returns keywords of both corpora ranked by the method, the most
+// frequent in a first, the most frequent in b last. If a corpus is empty,
+// every word is infinitely more frequent in the other one.
+func Compare(a, b []count.Element, m Method) []Keyword {
+	counts := map[string]*Keyword{}
+	var totalA, totalB uint64
+	for _, e := range a {
+		totalA += e.Count
+		counts[e.Key] = &Keyword{Word: e.Key, A: e.Count}
+	}
+	for _, e := range b {
+		totalB += e.Count
+		if k, ok := counts[e.Key]; ok {
+			k.B = e.Count
+		} else {
+			counts[e.Key] = &Keyword{Word: e.Key, B: e.Count}
+		}
+	}
+
+	res := make([]Keyword, 0, len(counts))
+	for _, k := range counts {
+		k.score(totalA, totalB)
+		res = append(res, *k)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		si, sj := res[i].Score(m), res[j].Score(m)
+		if si != sj {
+			return si > sj
+		}
+		// NOTE: ties, e.g. infinite scores, are ranked by counts
+		if res[i].A != res[j].A {
+			return res[i].A > res[j].A
+		}
+		if res[i].B != res[j].B {
+			return res[i].B < res[j].B
+		}
+		return res[i].Word < res[j].Word
+	})
+
+	return res
+}
+
+func (k *Keyword) score(totalA, totalB uint64) {
+	a, b := float64(k.A), float64(k.B)
+	c, d := float64(totalA), float64(totalB)
+
+	// NOTE: relative frequencies in an empty corpus are 0/0
+	switch {
+	case c == 0:
+		k.LogLikelihood, k.ChiSquared, k.PercentDiff = math.Inf(-1), math.Inf(-1), -100
+		return
+	case d == 0:
+		k.LogLikelihood, k.ChiSquared, k.PercentDiff = math.Inf(1), math.Inf(1), math.Inf(1)
+		return
+	}
+
+	sign := 1.0
+	if a/c < b/d {
+		sign = -1
+	}
+
+	// NOTE: expected values of the word and all other words in both corpora
+	expectedA := c * (a + b) / (c + d)
+	expectedB := d * (a + b) / (c + d)
+	expectedRestA := c * (c - a + d - b) / (c + d)
+	expectedRestB := d * (c - a + d - b) / (c + d)
+
+	k.LogLikelihood = sign * 2 * (xlogx(a, expectedA) + xlogx(b, expectedB))
+	k.ChiSquared = sign * (chi(a, expectedA) + chi(b, expectedB) +
+		chi(c-a, expectedRestA) + chi(d-b, expectedRestB))
+
+	switch {
+	case b == 0:
+		k.PercentDiff = math.Inf(1)
+	default:
+		k.PercentDiff = (a/c - b/d) * 100 / (b / d)
+	}
+}
+
+// xlogx returns o * ln(o / e), assuming 0 * ln(0) = 0.
+func xlogx(o, e float64) float64 {
+	if o == 0 {
+		return 0
+	}
+	return o * math.Log(o/e)
+}
+
+func chi(o, e float64) float64 {
+	if e == 0 {
+		return 0
+	}
+	return (o - e) * (o - e) / e
+}
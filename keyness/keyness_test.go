@@ -0,0 +1,96 @@
+package keyness
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_Compare(t *testing.T) {
+	kk := Compare([]count.Element{
+		{Key: "the", Count: 10},
+		{Key: "of", Count: 90},
+	}, []count.Element{
+		{Key: "the", Count: 5},
+		{Key: "of", Count: 94},
+		{Key: "and", Count: 1},
+	}, LogLikelihood)
+
+	if !assert.Len(t, kk, 3) {
+		return
+	}
+
+	assert.Equal(t, "the", kk[0].Word)
+	assert.Equal(t, uint64(10), kk[0].A)
+	assert.Equal(t, uint64(5), kk[0].B)
+	// NOTE: 2 * (10 * ln(10 / 7.5) + 5 * ln(5 / 7.5))
+	assert.InDelta(t, 1.698990, kk[0].LogLikelihood, 1e-6)
+	// NOTE: 2 * 2.5^2 / 7.5 + 2 * 2.5^2 / 92.5
+	assert.InDelta(t, 1.801802, kk[0].ChiSquared, 1e-6)
+	assert.InDelta(t, 100, kk[0].PercentDiff, 1e-9)
+
+	assert.Equal(t, "of", kk[1].Word)
+	assert.True(t, kk[1].LogLikelihood < 0)
+	assert.True(t, kk[1].ChiSquared < 0)
+	assert.True(t, kk[1].PercentDiff < 0)
+
+	// NOTE: 2 * 1 * ln(1 / 0.5)
+	assert.Equal(t, "and", kk[2].Word)
+	assert.InDelta(t, -1.386294, kk[2].LogLikelihood, 1e-6)
+	assert.Equal(t, -100.0, kk[2].PercentDiff)
+}
+
+func Test_Compare__missingInB(t *testing.T) {
+	kk := Compare([]count.Element{
+		{Key: "the", Count: 1},
+	}, []count.Element{
+		{Key: "of", Count: 1},
+	}, PercentDiff)
+
+	assert.Equal(t, "the", kk[0].Word)
+	assert.True(t, math.IsInf(kk[0].PercentDiff, 1))
+}
+
+func Test_Compare__empty(t *testing.T) {
+	b := []count.Element{
+		{Key: "the", Count: 3},
+		{Key: "of", Count: 1},
+	}
+
+	for _, m := range []Method{LogLikelihood, ChiSquared, PercentDiff} {
+		kk := Compare(nil, b, m)
+		if !assert.Len(t, kk, 2, m) {
+			continue
+		}
+
+		// NOTE: the most frequent word in b is the last one
+		assert.Equal(t, "of", kk[0].Word, m)
+		assert.Equal(t, "the", kk[1].Word, m)
+		for _, k := range kk {
+			assert.True(t, k.Score(m) < 0, "%s: %s", m, k.Word)
+		}
+
+		kk = Compare(b, nil, m)
+		if !assert.Len(t, kk, 2, m) {
+			continue
+		}
+
+		assert.Equal(t, "the", kk[0].Word, m)
+		assert.Equal(t, "of", kk[1].Word, m)
+		for _, k := range kk {
+			assert.True(t, k.Score(m) > 0, "%s: %s", m, k.Word)
+		}
+	}
+}
+
+func Test_ParseMethod(t *testing.T) {
+	m, err := ParseMethod("chi2")
+	assert.NoError(t, err)
+	assert.Equal(t, ChiSquared, m)
+
+	_, err = ParseMethod("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// kwic prints every occurrence of a word in the file with it's context.
+func kwic(args []string) error {
+	flags := flag.NewFlagSet("kwic", flag.ExitOnError)
+	word := flags.String("word", "", "word to find")
+	context := flags.Int("context", 30, "size of the context on each side")
+	tokens := flags.Bool("tokens", false, "measure context in words instead of characters")
+	batchSize := byteSizeVar(flags, "batch-size", batchSizeUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *word == "" {
+		return fmt.Errorf("usage: words kwic -word=X [-context=30] [-tokens] [-batch-size=auto] file")
+	}
+
+	oo, err := concordance(flags.Arg(0), int64(*batchSize), *word, *context, *tokens)
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, o := range oo {
+		if len(o.Left) > width {
+			width = len(o.Left)
+		}
+	}
+
+	for _, o := range oo {
+		fmt.Printf("%d:%d: %*s [%s] %s\n", o.Line, o.Offset, width, o.Left, o.Word, o.Right)
+	}
+	return nil
+}
+
+// occurrence is a single occurrence of a word with it's context.
+type occurrence struct {
+	// Offset is a byte offset of the word in the file.
+	Offset int64
+	// Line is 1-based line number of the word.
+	Line  int64
+	Left  string
+	Word  string
+	Right string
+
+	end            int64
+	batchOffset    int64
+	newlinesBefore int64
+}
+
+// concordance returns all occurrences of the word in the file ordered by
+// offset. Context is measured in characters, or in words if tokens is set.
+func concordance(filepath string, batchSize int64, word string, context int, tokens bool) ([]*occurrence, error) {
+	word = strings.ToLower(word)
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat `%s`: %s", filepath, err)
+	}
+
+	lines := newLineCounter()
+
+	guard := &sync.Mutex{}
+	oo := []*occurrence{}
+	if err := readBatchesAt(filepath, batchSize, nil, func(offset int64, batch []byte) error {
+		lines.add(offset, batch)
+
+		// NOTE: words can cross batch boundaries, so look at a byte before
+		// the batch to skip a word that started in the previous batch and
+		// at the bytes after the batch to see the whole last word.
+		ext, extOffset, err := extendBatch(file, offset, batch, maxWordLen)
+		if err != nil {
+			return err
+		}
+
+		found := []*occurrence{}
+		newlines, last := int64(0), 0
+		tokenize(ext, maxWordLen, func(w []byte, start, end int) {
+			pos := extOffset + int64(start)
+			if pos < offset || pos >= offset+int64(len(batch)) {
+				return
+			}
+			if end-start != len(word) || string(w) != word {
+				return
+			}
+
+			inBatch := int(pos - offset)
+			newlines += int64(bytes.Count(batch[last:inBatch], []byte{'\n'}))
+			last = inBatch
+
+			found = append(found, &occurrence{
+				Offset:         pos,
+				Word:           string(ext[start:end]),
+				end:            extOffset + int64(end),
+				batchOffset:    offset,
+				newlinesBefore: newlines,
+			})
+		})
+
+		guard.Lock()
+		oo = append(oo, found...)
+		guard.Unlock()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	lines.finish()
+
+	sort.Slice(oo, func(i, j int) bool { return oo[i].Offset < oo[j].Offset })
+
+	for _, o := range oo {
+		o.Line = lines.line(o.batchOffset, o.newlinesBefore)
+
+		if tokens {
+			o.Left, err = leftWords(file, o.Offset, context)
+			if err != nil {
+				return nil, err
+			}
+			o.Right, err = rightWords(file, info.Size(), o.end, context)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		o.Left, err = leftChars(file, o.Offset, context)
+		if err != nil {
+			return nil, err
+		}
+		o.Right, err = rightChars(file, o.end, context)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return oo, nil
+}
+
+// extendBatch returns the batch with a byte before and `after` bytes after it,
+// and offset of the extended batch.
+func extendBatch(r io.ReaderAt, offset int64, batch []byte, after int) ([]byte, int64, error) {
+	ext := make([]byte, 0, len(batch)+after+1)
+	extOffset := offset
+
+	if offset > 0 {
+		before := make([]byte, 1)
+		if _, err := r.ReadAt(before, offset-1); err != nil {
+			return nil, 0, err
+		}
+		ext = append(ext, before...)
+		extOffset--
+	}
+
+	ext = append(ext, batch...)
+
+	tail := make([]byte, after)
+	n, err := r.ReadAt(tail, offset+int64(len(batch)))
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	return append(ext, tail[:n]...), extOffset, nil
+}
+
+// leftChars returns text of n characters before the offset.
+func leftChars(r io.ReaderAt, offset int64, n int) (string, error) {
+	buf, err := readRange(r, offset-int64(n*utf8.UTFMax), offset)
+	if err != nil {
+		return "", err
+	}
+
+	// NOTE: the first character can be cut
+	for len(buf) > 0 && !utf8.RuneStart(buf[0]) {
+		buf = buf[1:]
+	}
+	for count := utf8.RuneCount(buf); count > n; count-- {
+		_, size := utf8.DecodeRune(buf)
+		buf = buf[size:]
+	}
+	return flatten(buf), nil
+}
+
+// rightChars returns text of n characters after the offset.
+func rightChars(r io.ReaderAt, offset int64, n int) (string, error) {
+	buf, err := readRange(r, offset, offset+int64(n*utf8.UTFMax))
+	if err != nil {
+		return "", err
+	}
+
+	end := 0
+	for i := 0; i < n && end < len(buf); i++ {
+		_, size := utf8.DecodeRune(buf[end:])
+		end += size
+	}
+	return flatten(buf[:end]), nil
+}
+
+// readRange returns bytes between offsets.
+func readRange(r io.ReaderAt, from, to int64) ([]byte, error) {
+	if from < 0 {
+		from = 0
+	}
+	if to <= from {
+		return nil, nil
+	}
+
+	buf := make([]byte, to-from)
+	n, err := r.ReadAt(buf, from)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// leftWords returns text of n words before the offset.
+func leftWords(r io.ReaderAt, offset int64, n int) (string, error) {
+	if n <= 0 {
+		return "", nil
+	}
+
+	for size := int64(n)*16 + 16; ; size *= 2 {
+		from := offset - size
+		if from < 0 {
+			from = 0
+		}
+
+		buf := make([]byte, offset-from)
+		if _, err := r.ReadAt(buf, from); err != nil && err != io.EOF {
+			return "", err
+		}
+
+		starts := []int{}
+		tokenize(buf, maxWordLen, func(_ []byte, start, _ int) {
+			starts = append(starts, start)
+		})
+
+		// NOTE: the first word can be cut
+		if from > 0 && len(starts) > 0 && starts[0] == 0 {
+			starts = starts[1:]
+		}
+
+		if len(starts) >= n {
+			return flatten(buf[starts[len(starts)-n]:]), nil
+		}
+		if from == 0 {
+			return flatten(buf), nil
+		}
+	}
+}
+
+// rightWords returns text of n words after the offset.
+func rightWords(r io.ReaderAt, size int64, offset int64, n int) (string, error) {
+	if n <= 0 {
+		return "", nil
+	}
+
+	for window := int64(n)*16 + 16; ; window *= 2 {
+		to := offset + window
+		if to > size {
+			to = size
+		}
+		if to <= offset {
+			return "", nil
+		}
+
+		buf := make([]byte, to-offset)
+		if _, err := r.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return "", err
+		}
+
+		ends := []int{}
+		tokenize(buf, maxWordLen, func(_ []byte, _, end int) {
+			ends = append(ends, end)
+		})
+
+		// NOTE: the last word can be cut
+		if to < size && len(ends) > 0 && ends[len(ends)-1] == len(buf) {
+			ends = ends[:len(ends)-1]
+		}
+
+		if len(ends) >= n {
+			return flatten(buf[:ends[n-1]]), nil
+		}
+		if to == size {
+			return flatten(buf), nil
+		}
+	}
+}
+
+var flattener = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+func flatten(b []byte) string {
+	return flattener.Replace(string(b))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const kwicCorpus = "The cat sat.\nA dog and the\ncat ran, cats\nrun; the CAT!"
+
+func Test_concordance(t *testing.T) {
+	file := writeTemp(t, "kwic", kwicCorpus)
+	defer os.Remove(file)
+
+	// NOTE: batch size splits words and contexts between batches
+	for _, batchSize := range []int64{5, 7, 1024} {
+		oo, err := concordance(file, batchSize, "Cat", 4, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !assert.Len(t, oo, 3, batchSize) {
+			continue
+		}
+
+		assert.Equal(t, int64(4), oo[0].Offset)
+		assert.Equal(t, int64(1), oo[0].Line)
+		assert.Equal(t, "The ", oo[0].Left)
+		assert.Equal(t, "cat", oo[0].Word)
+		assert.Equal(t, " sat", oo[0].Right)
+
+		assert.Equal(t, int64(27), oo[1].Offset)
+		assert.Equal(t, int64(3), oo[1].Line)
+		assert.Equal(t, "the ", oo[1].Left)
+		assert.Equal(t, " ran", oo[1].Right)
+
+		assert.Equal(t, int64(50), oo[2].Offset)
+		assert.Equal(t, int64(4), oo[2].Line)
+		assert.Equal(t, "CAT", oo[2].Word)
+		assert.Equal(t, "the ", oo[2].Left)
+		assert.Equal(t, "!", oo[2].Right)
+	}
+}
+
+func Test_concordance__runes(t *testing.T) {
+	file := writeTemp(t, "kwic", "éèê cat ñõü")
+	defer os.Remove(file)
+
+	// NOTE: context is measured in characters, not bytes
+	oo, err := concordance(file, 1024, "cat", 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, oo, 1) {
+		assert.Equal(t, "èê ", oo[0].Left)
+		assert.Equal(t, " ñõ", oo[0].Right)
+	}
+}
+
+func Test_concordance__tokens(t *testing.T) {
+	file := writeTemp(t, "kwic", kwicCorpus)
+	defer os.Remove(file)
+
+	oo, err := concordance(file, 6, "cat", 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Len(t, oo, 3) {
+		return
+	}
+
+	assert.Equal(t, "The ", oo[0].Left)
+	assert.Equal(t, " sat. A", oo[0].Right)
+	assert.Equal(t, "and the ", oo[1].Left)
+	assert.Equal(t, " ran, cats", oo[1].Right)
+	assert.Equal(t, "run; the ", oo[2].Left)
+	assert.Equal(t, "!", oo[2].Right)
+}
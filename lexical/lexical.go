@@ -0,0 +1,102 @@
+// Package lexical computes lexical diversity and readability metrics of a
+// text from word frequencies.
+package lexical
+
+import (
+	"github.com/ngalaiko/words/count"
+)
+
+// Metrics of a text.
+type Metrics struct {
+	// Tokens is a number of words.
+	Tokens uint64 `json:"tokens"`
+	// Types is a number of distinct words.
+	Types uint64 `json:"types"`
+	// Hapax is a number of words that occur only once.
+	Hapax uint64 `json:"hapax_legomena"`
+	// TypeTokenRatio is Types / Tokens.
+	TypeTokenRatio float64 `json:"type_token_ratio"`
+	// YuleK is Yule's characteristic K, the lower it is the more diverse is
+	// the vocabulary.
+	// https://en.wikipedia.org/wiki/Yule%27s_K
+	YuleK float64 `json:"yule_k"`
+	// Sentences is a number of sentences.
+	Sentences int64 `json:"sentences"`
+	// Syllables is a number of syllables.
+	Syllables uint64 `json:"syllables"`
+	// FleschReadingEase is a readability score, the higher it is the easier
+	// is the text to read.
+	// https://en.wikipedia.org/wiki/Flesch%E2%80%93Kincaid_readability_tests
+	FleschReadingEase float64 `json:"flesch_reading_ease"`
+}
+
+// Compute returns metrics of a text with given word frequencies and number
+// of sentences.
+func Compute(words []count.Element, sentences int64) *Metrics {
+	m := &Metrics{
+		Types:     uint64(len(words)),
+		Sentences: sentences,
+	}
+
+	var squares float64
+	for _, w := range words {
+		m.Tokens += w.Count
+		m.Syllables += uint64(Syllables(w.Key)) * w.Count
+		if w.Count == 1 {
+			m.Hapax++
+		}
+		squares += float64(w.Count) * float64(w.Count)
+	}
+
+	if m.Tokens == 0 {
+		return m
+	}
+
+	// NOTE: a text without sentence terminators is a single sentence
+	if m.Sentences == 0 {
+		m.Sentences = 1
+	}
+
+	tokens := float64(m.Tokens)
+	m.TypeTokenRatio = float64(m.Types) / tokens
+	m.YuleK = 1e4 * (squares - tokens) / (tokens * tokens)
+	m.FleschReadingEase = 206.835 -
+		1.015*(tokens/float64(m.Sentences)) -
+		84.6*(float64(m.Syllables)/tokens)
+
+	return m
+}
+
+// Syllables estimates a number of syllables in a lowercase english word by
+// counting groups of vowels.
+func Syllables(word string) int {
+	n := 0
+	prevVowel := false
+	for i := 0; i < len(word); i++ {
+		vowel := isVowel(word[i])
+		if vowel && !prevVowel {
+			n++
+		}
+		prevVowel = vowel
+	}
+
+	// NOTE: trailing 'e' is usually silent, except for "-le" as in "table"
+	l := len(word)
+	if n > 1 && word[l-1] == 'e' && !(l > 2 && word[l-2] == 'l' && !isVowel(word[l-3])) {
+		n--
+	}
+
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	default:
+		return false
+	}
+}
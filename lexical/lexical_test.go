@@ -0,0 +1,66 @@
+package lexical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_Compute(t *testing.T) {
+	// NOTE: "The cat saw the dog. The dog ran!"
+	m := Compute([]count.Element{
+		{Key: "the", Count: 3},
+		{Key: "dog", Count: 2},
+		{Key: "cat", Count: 1},
+		{Key: "saw", Count: 1},
+		{Key: "ran", Count: 1},
+	}, 2)
+
+	assert.Equal(t, uint64(8), m.Tokens)
+	assert.Equal(t, uint64(5), m.Types)
+	assert.Equal(t, uint64(3), m.Hapax)
+	assert.Equal(t, uint64(8), m.Syllables)
+	assert.InDelta(t, 0.625, m.TypeTokenRatio, 1e-9)
+	// NOTE: 10^4 * (3^2 + 2^2 + 1 + 1 + 1 - 8) / 8^2
+	assert.InDelta(t, 1250, m.YuleK, 1e-9)
+	// NOTE: 206.835 - 1.015 * 8/2 - 84.6 * 8/8
+	assert.InDelta(t, 118.175, m.FleschReadingEase, 1e-9)
+}
+
+func Test_Compute__longWords(t *testing.T) {
+	// NOTE: "Readability formulas estimate difficulty"
+	m := Compute([]count.Element{
+		{Key: "readability", Count: 1},
+		{Key: "formulas", Count: 1},
+		{Key: "estimate", Count: 1},
+		{Key: "difficulty", Count: 1},
+	}, 0)
+
+	assert.Equal(t, int64(1), m.Sentences)
+	assert.Equal(t, uint64(4), m.Hapax)
+	assert.Equal(t, uint64(15), m.Syllables)
+	assert.InDelta(t, 0, m.YuleK, 1e-9)
+	// NOTE: 206.835 - 1.015 * 4/1 - 84.6 * 15/4
+	assert.InDelta(t, -114.475, m.FleschReadingEase, 1e-9)
+}
+
+func Test_Compute__empty(t *testing.T) {
+	assert.Equal(t, &Metrics{}, Compute(nil, 0))
+}
+
+func Test_Syllables(t *testing.T) {
+	for word, expected := range map[string]int{
+		"the":         1,
+		"make":        1,
+		"table":       2,
+		"reading":     2,
+		"beautiful":   3,
+		"readability": 5,
+		"rhythm":      1,
+		"be":          1,
+	} {
+		assert.Equal(t, expected, Syllables(word), word)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// lineCounter maps byte offsets to line numbers when batches are processed
+// out of order. Every batch reports number of newlines in it, once all
+// batches are processed, line of an offset is a sum of newlines in all
+// previous batches plus newlines before the offset in it's batch.
+type lineCounter struct {
+	guard   sync.Mutex
+	batches map[int64]*batchLines
+
+	offsets    []int64
+	before     map[int64]int64
+	lastBefore map[int64]int64
+}
+
+type batchLines struct {
+	newlines int64
+	// last is an offset of the last newline in the batch, or -1
+	last int64
+}
+
+func newLineCounter() *lineCounter {
+	return &lineCounter{
+		batches: map[int64]*batchLines{},
+	}
+}
+
+// add records newlines of the batch at offset.
+func (l *lineCounter) add(offset int64, batch []byte) {
+	bl := &batchLines{
+		newlines: int64(bytes.Count(batch, []byte{'\n'})),
+		last:     -1,
+	}
+	if i := bytes.LastIndexByte(batch, '\n'); i != -1 {
+		bl.last = offset + int64(i)
+	}
+
+	l.guard.Lock()
+	l.batches[offset] = bl
+	l.guard.Unlock()
+}
+
+// finish computes prefix sums of newlines, it must be called after all
+// batches are added.
+func (l *lineCounter) finish() {
+	l.offsets = make([]int64, 0, len(l.batches))
+	for offset := range l.batches {
+		l.offsets = append(l.offsets, offset)
+	}
+	sort.Slice(l.offsets, func(i, j int) bool { return l.offsets[i] < l.offsets[j] })
+
+	l.before = make(map[int64]int64, len(l.offsets))
+	l.lastBefore = make(map[int64]int64, len(l.offsets))
+	var total int64
+	last := int64(-1)
+	for _, offset := range l.offsets {
+		l.before[offset] = total
+		l.lastBefore[offset] = last
+
+		bl := l.batches[offset]
+		total += bl.newlines
+		if bl.last != -1 {
+			last = bl.last
+		}
+	}
+}
+
+// line returns 1-based line number of a position, where batchOffset is
+// offset of the batch and inBatch is a number of newlines in the batch
+// before the position.
+func (l *lineCounter) line(batchOffset int64, inBatch int64) int64 {
+	return l.before[batchOffset] + inBatch + 1
+}
+
+// position returns 1-based line and column of the offset, where inBatch is a
+// number of newlines in the batch before the offset and lastNewline is an
+// offset of the last of them, or -1 if there are none.
+func (l *lineCounter) position(offset int64, inBatch int64, lastNewline int64) (int64, int64) {
+	i := sort.Search(len(l.offsets), func(i int) bool { return l.offsets[i] > offset }) - 1
+	if i < 0 {
+		return inBatch + 1, offset + 1
+	}
+
+	batchOffset := l.offsets[i]
+	if lastNewline == -1 {
+		lastNewline = l.lastBefore[batchOffset]
+	}
+	return l.line(batchOffset, inBatch), offset - lastNewline
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_fromFile__positions(t *testing.T) {
+	// NOTE: "the" is at 1:1, 2:5, 4:1 and 4:9
+	file := writeTemp(t, "positions", "The cat\nsaw the dog\n\nthe cat the end")
+	defer os.Remove(file)
+
+	expected := []count.Position{
+		{Offset: 0, Line: 1, Column: 1},
+		{Offset: 12, Line: 2, Column: 5},
+		{Offset: 21, Line: 4, Column: 1},
+		{Offset: 29, Line: 4, Column: 9},
+	}
+
+	// NOTE: small batches put words and newlines into different batches,
+	// but don't split words
+	for _, batchSize := range []int64{8, 12, 1024} {
+		positions := count.NewPositions(3, 10, 0)
+		if _, err := fromFile(file, batchSize, count.New(10), &options{
+			positions: positions,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		wp, ok := positions.Get("the")
+		if !assert.True(t, ok, batchSize) {
+			continue
+		}
+
+		assert.Equal(t, expected[:3], wp.First, batchSize)
+		assert.Equal(t, expected, wp.Sample, batchSize)
+	}
+}
@@ -1,25 +1,102 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
 
-	"golang.org/x/sync/errgroup"
-
+	"github.com/ngalaiko/words/analytics"
+	"github.com/ngalaiko/words/charset"
+	"github.com/ngalaiko/words/common"
 	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/markup"
+	"github.com/ngalaiko/words/metrics"
+	"github.com/ngalaiko/words/normalize"
+	"github.com/ngalaiko/words/zipf"
 )
 
 var filePath = flag.String("file", "", "path to input file")
 var topN = flag.Int("n", 10, "top N words")
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to `file`")
 var memprofile = flag.String("memprofile", "", "write memory profile to `file`")
+var metricsAddr = flag.String("metrics-addr", "", "serve prometheus metrics on `address` while running")
+var metricsTopN = flag.Int("metrics-top-n", 0, "export top N words as metrics")
+var showProgress = flag.Bool("progress", false, "report progress to stderr")
+var showStats = flag.Bool("stats", false, "print stats of the run")
+var analyticsFormat = flag.String("analytics", "", "print token shape statistics as `text|json`")
+var zipfReport = flag.Bool("zipf", false, "print zipf's law fit of all words")
+var zipfCSV = flag.String("zipf-csv", "", "write rank-frequency table of all words to `file`")
+var firstPositions = flag.Int("positions", 0, "print first `N` positions of every top word")
+var samplePositions = flag.Int("positions-sample", 0, "print `N` randomly sampled positions of every top word")
+var normalization = flag.String("normalize", "none", "count inflected forms together: `none|stem|lemma`")
+var apostrophes = flag.Bool("apostrophes", false, "keep apostrophes inside words, e.g. \"don't\"")
+var expandContractions = flag.Bool("contractions", false, "expand contractions, e.g. \"don't\" to \"do not\"")
+var hyphens = flag.Bool("hyphens", false, "keep hyphens inside words, e.g. \"well-known\"")
+var underscores = flag.Bool("underscores", false, "treat underscores as word characters, e.g. \"error_code\"")
+var digits = flag.Bool("digits", false, "treat digits as word characters, e.g. \"v2\"")
+var dots = flag.Bool("dots", false, "keep dots inside words, e.g. \"os.path\"")
+var countAllWords = flag.Bool("all", false, "count all words, not only the most common ones")
+var tokenPattern = flag.String("pattern", "", "count matches of the `regexp` instead of words")
+var tokenPatternGroup = flag.Int("pattern-group", 0, "count only the capturing `group` of -pattern matches")
+var csvColumnFlag = flag.String("csv-column", "", "count words only in the csv column with the `name|index`")
+var tsvColumnFlag = flag.String("tsv-column", "", "count words only in the tsv column with the `name|index`")
+var jsonlFieldFlag = flag.String("jsonl-field", "", "count words only in the json lines field at the dotted `path`")
+var inputFormat = flag.String("input-format", "", "strip markup of the input: `text|html|xml|markdown`, detected by extension by default")
+var encodingName = flag.String("encoding", "", "decode the input from `utf8|utf16le|utf16be|latin1|cp1252`, detected by byte order mark by default")
+var lineBatches = flag.Bool("lines", false, "split batches by lines, so words are never split by them")
+var batchSizeFlag = byteSizeVar(flag.CommandLine, "batch-size", batchSizeUsage)
+var caseMode = flag.String("case", "fold", "count words `fold|sensitive|preserve-most-common`")
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			if err := serve(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "analyze":
+			if err := analyze(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "diff":
+			if err := diff(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "tfidf":
+			if err := rankTFIDF(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "cooccur":
+			if err := countCooccurrences(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "kwic":
+			if err := kwic(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "source":
+			if err := countSource(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if *cpuprofile != "" {
@@ -34,10 +111,181 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	switch *analyticsFormat {
+	case "", "text", "json":
+	default:
+		log.Fatalf("unknown analytics format: `%s`", *analyticsFormat)
+	}
+
+	normalizeFn, err := normalize.New(normalize.Mode(*normalization))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if normalizeFn != nil {
+		normalizeFn = normalize.Canonical(normalizeFn, common.List())
+	}
+
+	t := &tokenizer{
+		apostrophes:  *apostrophes,
+		contractions: *expandContractions,
+		hyphens:      *hyphens,
+		underscores:  *underscores,
+		digits:       *digits,
+		dots:         *dots,
+	}
+
+	var forms *count.Forms
+	switch *caseMode {
+	case "fold":
+	case "sensitive":
+		t.keepCase = true
+	case "preserve-most-common":
+		t.keepCase = true
+		forms = count.NewForms()
+	default:
+		log.Fatalf("unknown case mode: `%s`", *caseMode)
+	}
+
+	enc, err := detectEncoding(*encodingName, *filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if enc != "" {
+		t.unicode = true
+	}
+
+	if *t == (tokenizer{}) {
+		t = nil
+	}
+
+	var pt *pattern
+	if *tokenPattern != "" {
+		if pt, err = newPattern(*tokenPattern, *tokenPatternGroup); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var column *csvColumn
+	switch {
+	case *csvColumnFlag != "" && *tsvColumnFlag != "":
+		log.Fatal("-csv-column and -tsv-column can't be used together")
+	case *csvColumnFlag != "":
+		column = newCSVColumn(*csvColumnFlag, ',')
+	case *tsvColumnFlag != "":
+		column = newCSVColumn(*tsvColumnFlag, '\t')
+	}
+
+	var field *jsonlField
+	if *jsonlFieldFlag != "" {
+		if column != nil {
+			log.Fatal("-jsonl-field can't be used together with -csv-column or -tsv-column")
+		}
+		field = newJSONLField(*jsonlFieldFlag)
+	}
+
+	format, err := markup.Parse(*inputFormat, *filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if (column != nil || field != nil || format != markup.Text) && (*firstPositions > 0 || *samplePositions > 0) {
+		log.Fatal("positions are not supported for csv, tsv, json lines and markup input")
+	}
+	if (column != nil || field != nil || format != markup.Text) && *lineBatches {
+		log.Fatal("-lines is not supported for csv, tsv, json lines and markup input")
+	}
+
+	if enc != "" && enc != charset.UTF8 {
+		switch {
+		case *firstPositions > 0 || *samplePositions > 0:
+			log.Fatalf("positions are not supported for %s input", enc)
+		case field != nil:
+			log.Fatalf("json lines must be utf-8, not %s", enc)
+		case charset.UnitSize(enc) > 1 && (column != nil || format != markup.Text || pt != nil):
+			log.Fatalf("%s is not supported for pattern, csv, tsv and markup input", enc)
+		}
+	}
+
 	tk := count.New(*topN)
-	err := fromFile(*filePath, 2<<19-1, tk)
-	for _, e := range tk.Keys() {
-		fmt.Printf("%d: %s\n", e.Count, e.Key)
+	if *countAllWords || pt != nil {
+		tk = count.NewUnfiltered(*topN)
+	}
+
+	if *metricsAddr != "" {
+		if *metricsTopN > 0 {
+			registry.NewGaugeFunc(topWordsMetric, topWordsHelp, func() []metrics.Sample {
+				return topSamples(tk, *metricsTopN)
+			})
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", registry)
+		go func() {
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				log.Printf("failed to serve metrics: %s", err)
+			}
+		}()
+	}
+
+	var p *progress
+	stopProgress := func() {}
+	if *showProgress {
+		p = newProgress()
+		stopProgress = p.start(os.Stderr)
+	}
+
+	var positions *count.Positions
+	if *firstPositions > 0 || *samplePositions > 0 {
+		positions = count.NewPositions(*firstPositions, *samplePositions, time.Now().UnixNano())
+	}
+
+	opts := &options{
+		progress:  p,
+		positions: positions,
+		normalize: normalizeFn,
+		tokenizer: t,
+		pattern:   pt,
+		csv:       column,
+		jsonl:     field,
+		markup:    format,
+		encoding:  enc,
+		lines:     *lineBatches,
+		forms:     forms,
+	}
+	stats, err := fromFile(*filePath, int64(*batchSizeFlag), tk, opts)
+	stopProgress()
+
+	top := topWords(tk, *topN)
+	if forms != nil {
+		top = forms.Annotate(top)
+	}
+
+	for _, e := range top {
+		word := e.Key
+		if e.Form != "" {
+			word = e.Form
+		}
+		fmt.Printf("%d: %s\n", e.Count, word)
+
+		if positions == nil {
+			continue
+		}
+		if wp, ok := positions.Get(e.Key); ok {
+			printPositions("first", wp.First)
+			printPositions("sample", wp.Sample)
+		}
+	}
+
+	if *showStats && stats != nil {
+		fmt.Printf("\n%s", stats)
+	}
+
+	if err == nil && *analyticsFormat != "" {
+		err = printAnalytics(*filePath, int64(*batchSizeFlag), *analyticsFormat)
+	}
+
+	if err == nil && (*zipfReport || *zipfCSV != "") {
+		err = printZipf(*filePath, int64(*batchSizeFlag), tk, opts, *zipfCSV)
 	}
 
 	if *memprofile != "" {
@@ -57,78 +305,421 @@ func main() {
 	}
 }
 
-func fromFile(filepath string, batchSize int64, tk *count.Stream) error {
-	file, err := os.Open(filepath)
+// topWords returns n most frequent words of the stream.
+func topWords(tk *count.Stream, n int) []count.Element {
+	if !tk.Filtered() {
+		// NOTE: Keys keeps a single word of every count, which is good enough
+		// only for the most common words
+		return tk.TopN(n)
+	}
+	return tk.Keys()
+}
+
+// printAnalytics prints token shape statistics of the file in the format.
+func printAnalytics(filepath string, batchSize int64, format string) error {
+	report, err := analyzeFile(filepath, batchSize)
 	if err != nil {
-		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+		return err
 	}
-	defer file.Close()
 
-	info, err := file.Stat()
+	fmt.Println()
+	switch format {
+	case "text":
+		return report.WriteText(os.Stdout)
+	case "json":
+		return report.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unknown analytics format: `%s`", format)
+	}
+}
+
+// printPositions prints positions as line:column@offset.
+func printPositions(label string, pp []count.Position) {
+	if len(pp) == 0 {
+		return
+	}
+
+	formatted := make([]string, 0, len(pp))
+	for _, pos := range pp {
+		formatted = append(formatted, fmt.Sprintf("%d:%d@%d", pos.Line, pos.Column, pos.Offset))
+	}
+	fmt.Printf("\t%s: %s\n", label, strings.Join(formatted, ", "))
+}
+
+// printZipf prints zipf's law fit of all words of the file counted by tk with
+// the opts. If csvPath is not empty, rank-frequency table is written there.
+func printZipf(filepath string, batchSize int64, tk *count.Stream, opts *options, csvPath string) error {
+	table, err := zipfTable(filepath, batchSize, tk, opts)
 	if err != nil {
-		return fmt.Errorf("failed to stat `%s`: %s", filepath, err)
+		return err
+	}
+
+	fmt.Printf("\n%s", zipf.FitTable(table))
+
+	if csvPath == "" {
+		return nil
+	}
+
+	f, err := os.Create(csvPath)
+	if err != nil {
+		return fmt.Errorf("failed to create `%s`: %s", csvPath, err)
+	}
+	defer f.Close()
+
+	if err := zipf.WriteCSV(f, table); err != nil {
+		return fmt.Errorf("failed to write `%s`: %s", csvPath, err)
+	}
+	return f.Close()
+}
+
+// zipfTable returns rank-frequency table of all words of the file counted by
+// tk with the opts. If tk counts only the most common words, the file is
+// counted again with the same opts.
+func zipfTable(filepath string, batchSize int64, tk *count.Stream, opts *options) ([]zipf.Rank, error) {
+	if tk.Filtered() {
+		all := count.NewUnfiltered(0)
+
+		o := &options{}
+		if opts != nil {
+			*o = *opts
+		}
+		o.progress, o.positions = nil, nil
+		// NOTE: batches of whole lines don't split words, csv, json lines
+		// and markup input is split by records, lines and tags anyway
+		o.lines = true
+		if o.forms != nil {
+			// NOTE: forms make words lowercase, but they are counted
+			// already
+			o.forms = count.NewForms()
+		}
+
+		if _, err := fromFile(filepath, batchSize, all, o); err != nil {
+			return nil, err
+		}
+		tk = all
+	}
+	return zipf.Table(tk.TopN(tk.Len())), nil
+}
+
+// options of fromFile, nil options are valid.
+type options struct {
+	// progress is updated with number of processed bytes.
+	progress *progress
+	// positions records positions of counted words.
+	positions *count.Positions
+	// normalize maps words to their normalized form before counting.
+	normalize normalize.Func
+	// tokenizer splits batches into words.
+	tokenizer *tokenizer
+	// pattern, if set, is used instead of tokenizer and makes batches
+	// line-aligned, so matches don't cross batches.
+	pattern *pattern
+	// csv, if set, makes batches record-aligned and only values of the
+	// column are counted. Positions are not supported then.
+	csv *csvColumn
+	// jsonl, if set, makes batches line-aligned and only values of the
+	// field are counted. Positions are not supported then.
+	jsonl *jsonlField
+	// markup of the input is stripped if it's set and not markup.Text.
+	// Positions are not supported then.
+	markup markup.Format
+	// encoding of the input, batches are decoded to utf-8 if it's set and
+	// not charset.UTF8. Positions are not supported then.
+	encoding charset.Encoding
+	// lines makes batches line-aligned.
+	lines bool
+	// forms, if set, records surface forms of words that are counted
+	// lowercased.
+	forms *count.Forms
+}
+
+// fromFile counts words of the file reading it in batches concurrently and
+// returns stats of the run. If batchSize is not positive, it's picked by
+// the file size.
+func fromFile(filepath string, batchSize int64, tk *count.Stream, opts *options) (*Stats, error) {
+	start := time.Now()
+
+	if batchSize <= 0 {
+		var err error
+		if batchSize, err = fileBatchSize(filepath); err != nil {
+			return nil, err
+		}
+	}
+
+	var p *progress
+	if opts != nil {
+		p = opts.progress
 	}
 
-	all := info.Size() / batchSize
-	wg := &errgroup.Group{}
-	for i := int64(0); i < all; i++ {
-		i := i
-		// NOTE: read concurrently and process in batch
-		wg.Go(func() error {
+	// NOTE: normalized forms, contractions and uncommon words can be longer
+	// than 4 letters, so words can't be truncated
+	wordLen := maxLen
+	if !tk.Filtered() || opts != nil && (opts.normalize != nil || opts.tokenizer != nil || opts.pattern != nil) {
+		wordLen = maxWordLen
+	}
+
+	stats := &Stats{
+		BatchSize: batchSize,
+	}
 
-			buff := make([]byte, batchSize)
+	var enc charset.Encoding
+	if opts != nil && opts.encoding != charset.UTF8 {
+		enc = opts.encoding
+	}
 
-			off, err := file.ReadAt(buff, batchSize*i)
-			switch err {
-			case nil:
-			case io.EOF:
-				return nil
-			default:
-				return err
+	// NOTE: markup batches are decoded by readMarkupAt before they are
+	// stripped
+	decode := enc
+	read := readBatchesAt
+	switch {
+	case opts == nil:
+	case charset.UnitSize(enc) > 1:
+		split := splitUnits(enc)
+		if opts.lines {
+			split = splitUnitLines(enc)
+		}
+		read = func(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+			return readSplitBatchesAt(filepath, batchSize, p, split, fn)
+		}
+	case opts.csv != nil:
+		read = opts.csv.readBatchesAt
+	case opts.jsonl != nil:
+		read = func(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+			var err error
+			stats.MalformedLines, err = opts.jsonl.readBatchesAt(filepath, batchSize, p, fn)
+			return err
+		}
+	case opts.markup != "" && opts.markup != markup.Text:
+		decode = ""
+		read = func(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+			return readMarkupAt(opts.markup, enc, filepath, batchSize, p, fn)
+		}
+	case opts.pattern != nil || opts.lines:
+		read = readLinesAt
+	}
+
+	lines := newLineCounter()
+	statsGuard := &sync.Mutex{}
+	if err := read(filepath, batchSize, p, func(offset int64, batch []byte) error {
+		if opts != nil && opts.positions != nil {
+			lines.add(offset, batch)
+		}
+
+		if offset == 0 && opts != nil {
+			// NOTE: byte order mark is not a part of the text
+			if bom := charset.BOM(opts.encoding); bom != nil && bytes.HasPrefix(batch, bom) {
+				batch, offset = batch[len(bom):], offset+int64(len(bom))
 			}
+		}
+
+		if decode != "" {
+			batch = charset.Decode(decode, make([]byte, 0, len(batch)), batch)
+		}
 
-			processBatch(buff[:off], maxLen, tk)
+		batchStats := processBatchAt(offset, batch, wordLen, tk, opts)
+
+		statsGuard.Lock()
+		stats.merge(batchStats)
+		statsGuard.Unlock()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
-			return nil
+	if opts != nil && opts.positions != nil {
+		lines.finish()
+		opts.positions.Resolve(func(offset int64, hint count.Hint) (int64, int64) {
+			return lines.position(offset, hint.Newlines, hint.LastNewline)
 		})
 	}
 
-	return wg.Wait()
+	stats.DistinctWords = tk.Len()
+	stats.Elapsed = time.Since(start)
+
+	return stats, nil
 }
 
-const maxLen = 4
+// countAll counts all words of the file, not only the most common ones.
+func countAll(filepath string, batchSize int64) (*count.Stream, error) {
+	tk := count.NewUnfiltered(0)
+	// NOTE: batches of whole lines don't split words
+	if err := readLinesAt(filepath, batchSize, nil, func(_ int64, batch []byte) error {
+		processBatch(batch, maxWordLen, tk)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tk, nil
+}
 
-// returns number of bytes processed
-func processBatch(batch []byte, maxLen int, tk *count.Stream) {
-	wordBuf := make([]byte, maxLen)
-	wordPos := 0
+// readBatchesAt reads the file in batches concurrently and calls fn for every
+// batch and it's offset in the file. If p is not nil, it's updated with number
+// of processed bytes.
+func readBatchesAt(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+	return readSplitBatchesAt(filepath, batchSize, p, splitBytes, fn)
+}
 
-	// TODO: there is a case when a word is splitted by a buffered read
-	// NOTE: I don't care
+// readLinesAt is like readBatchesAt, but every batch consists of whole lines.
+// Batches are longer or shorter than batchSize, a line longer than batchSize
+// is a single batch.
+func readLinesAt(filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+	return readSplitBatchesAt(filepath, batchSize, p, splitLines, fn)
+}
 
-	for _, c := range batch {
-		switch {
-		case c >= 'A' && c <= 'Z':
-			c += 32
-			fallthrough
-		case c >= 'a' && c <= 'z':
-			if wordPos == maxLen {
-				continue
-			}
-			wordBuf[wordPos] = c
-			wordPos++
-		default:
-			if wordPos == 0 {
-				continue
-			}
+// readMarkupAt is like readBatchesAt, but fn gets text of the batch with
+// markup of the format stripped. Batches don't split tags and code blocks.
+// If enc is not empty, batches are decoded from it to utf-8.
+func readMarkupAt(format markup.Format, enc charset.Encoding, filepath string, batchSize int64, p *progress, fn func(offset int64, batch []byte) error) error {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+	defer file.Close()
 
-			tk.Insert(string(wordBuf[:wordPos]))
+	boundaries, err := markup.Boundaries(file, format, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
 
-			wordPos = 0
+	return readSplitBatchesAt(filepath, batchSize, p, splitAt(boundaries, 0), func(offset int64, batch []byte) error {
+		// NOTE: entities are decoded to utf-8, so the batch is decoded
+		// before
+		if enc != "" {
+			batch = charset.Decode(enc, make([]byte, 0, len(batch)), batch)
 		}
+		return fn(offset, markup.NewStripper(format).Strip(make([]byte, 0, len(batch)), batch))
+	})
+}
+
+// detectEncoding returns the encoding by it's name, or detects it by byte
+// order mark of the file if the name is empty. Empty encoding means there
+// is no byte order mark and the input is treated as ascii.
+func detectEncoding(name string, filepath string) (charset.Encoding, error) {
+	if name != "" {
+		return charset.Parse(name)
+	}
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read `%s`: %s", filepath, err)
 	}
+	defer file.Close()
+
+	bom := make([]byte, 3)
+	n, err := file.Read(bom)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+	return charset.Sniff(bom[:n]), nil
+}
+
+// analyzeFile collects token shape statistics of the file.
+func analyzeFile(filepath string, batchSize int64) (*analytics.Report, error) {
+	report := analytics.New()
+	reportGuard := &sync.Mutex{}
+	// NOTE: tokens and characters never cross lines, so they are not split
+	// by batches
+	if err := readLinesAt(filepath, batchSize, nil, func(_ int64, batch []byte) error {
+		batchReport := analytics.Batch(batch)
+
+		reportGuard.Lock()
+		report.Merge(batchReport)
+		reportGuard.Unlock()
 
-	if wordPos > 0 && wordPos <= maxLen {
-		tk.Insert(string(wordBuf[:wordPos]))
+		return nil
+	}); err != nil {
+		return nil, err
 	}
+	return report, nil
+}
+
+const maxLen = 4
+
+// processBatch counts words of the batch, records pipeline metrics and
+// returns stats of the batch.
+func processBatch(batch []byte, maxLen int, tk *count.Stream) *Stats {
+	return processBatchAt(0, batch, maxLen, tk, nil)
+}
+
+// processBatchAt is processBatch of the batch at offset, it also normalizes
+// words and records positions of counted words if opts say so.
+func processBatchAt(offset int64, batch []byte, maxLen int, tk *count.Stream, opts *options) *Stats {
+	start := time.Now()
+	stats := &Stats{
+		Bytes:   int64(len(batch)),
+		Batches: 1,
+	}
+
+	var positions *count.Positions
+	var normalizeFn normalize.Func
+	var t *tokenizer
+	var pt *pattern
+	var forms *count.Forms
+	if opts != nil {
+		positions = opts.positions
+		normalizeFn = opts.normalize
+		t = opts.tokenizer
+		pt = opts.pattern
+		forms = opts.forms
+	}
+
+	tokenizeFn := t.tokenize
+	if pt != nil {
+		tokenizeFn = pt.tokenize
+	}
+
+	newlines, lastNewline, scanned := int64(0), int64(-1), 0
+	tokenizeFn(batch, maxLen, func(word []byte, start, end int) {
+		w := string(word)
+		form := w
+
+		// NOTE: words are lowercase, unless the tokenizer keeps case
+		key := w
+		if t != nil && t.keepCase {
+			key = strings.ToLower(w)
+		}
+
+		switch {
+		case normalizeFn != nil:
+			// NOTE: normalization expects lowercase words
+			w = normalizeFn(key)
+			key = w
+		case forms != nil:
+			w = key
+		}
+
+		matched := tk.InsertAs(w, key)
+		stats.addToken(batch[start:end], matched)
+
+		if matched && forms != nil {
+			forms.Insert(w, form)
+		}
+
+		if !matched || positions == nil {
+			return
+		}
+
+		for ; scanned < start; scanned++ {
+			if batch[scanned] == '\n' {
+				newlines++
+				lastNewline = offset + int64(scanned)
+			}
+		}
+
+		// NOTE: line and column are known only after all batches are
+		// processed, see lineCounter.position
+		positions.Insert(w, offset+int64(start), count.Hint{
+			Newlines:    newlines,
+			LastNewline: lastNewline,
+		})
+	})
+
+	bytesProcessed.Add(uint64(stats.Bytes))
+	batchesProcessed.Inc()
+	tokensSeen.Add(stats.Tokens)
+	tokensMatched.Add(stats.MatchedTokens)
+	batchDuration.Observe(time.Since(start).Seconds())
+
+	return stats
 }
@@ -6,11 +6,17 @@ import (
 	"log"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ngalaiko/words/charset"
 	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/markup"
+	"github.com/ngalaiko/words/normalize"
+	"github.com/ngalaiko/words/zipf"
 )
 
 func Test_processBatch(t *testing.T) {
@@ -36,6 +42,78 @@ that THAT that that (ThAt)
 	assert.Equal(t, 5, resMap["that"])
 }
 
+func Test_topWords(t *testing.T) {
+	tk := count.NewUnfiltered(10)
+	processBatch([]byte("error_code warn error_code info"), maxWordLen, tk)
+
+	// NOTE: words of the same count are not dropped
+	assert.Equal(t, []count.Element{
+		{Key: "code", Count: 2},
+		{Key: "error", Count: 2},
+		{Key: "info", Count: 1},
+	}, topWords(tk, 3))
+}
+
+func Test_processBatchAt__case(t *testing.T) {
+	batch := "US and us, The the THE"
+
+	tk := count.NewUnfiltered(10)
+	processBatchAt(0, []byte(batch), maxWordLen, tk, &options{
+		tokenizer: &tokenizer{keepCase: true},
+	})
+	assert.Equal(t, []count.Element{
+		{Key: "THE", Count: 1},
+		{Key: "The", Count: 1},
+		{Key: "US", Count: 1},
+		{Key: "and", Count: 1},
+		{Key: "the", Count: 1},
+		{Key: "us", Count: 1},
+	}, tk.TopN(10))
+
+	tk = count.NewUnfiltered(10)
+	forms := count.NewForms()
+	processBatchAt(0, []byte(batch), maxWordLen, tk, &options{
+		tokenizer: &tokenizer{keepCase: true},
+		forms:     forms,
+	})
+	assert.Equal(t, []count.Element{
+		{Key: "the", Count: 3, Form: "THE"},
+		{Key: "us", Count: 2, Form: "US"},
+		{Key: "and", Count: 1, Form: "and"},
+	}, forms.Annotate(tk.TopN(10)))
+
+	// NOTE: filtered streams match words regardless of their case
+	tk = count.New(10)
+	processBatchAt(0, []byte(batch), maxWordLen, tk, &options{
+		tokenizer: &tokenizer{keepCase: true},
+	})
+	assert.Equal(t, []count.Element{
+		{Key: "THE", Count: 1},
+		{Key: "The", Count: 1},
+		{Key: "US", Count: 1},
+		{Key: "and", Count: 1},
+		{Key: "the", Count: 1},
+		{Key: "us", Count: 1},
+	}, tk.TopN(10))
+}
+
+func Test_processBatchAt__caseNormalize(t *testing.T) {
+	stem, err := normalize.New(normalize.Stem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: words are stemmed lowercase
+	tk := count.NewUnfiltered(10)
+	processBatchAt(0, []byte("Running RUNS runs"), maxWordLen, tk, &options{
+		tokenizer: &tokenizer{keepCase: true},
+		normalize: stem,
+	})
+	assert.Equal(t, []count.Element{
+		{Key: "run", Count: 3},
+	}, tk.TopN(10))
+}
+
 func Test(t *testing.T) {
 	file, err := ioutil.TempFile("assets", "test")
 	if err != nil {
@@ -72,7 +150,7 @@ func Test(t *testing.T) {
 	}
 
 	tk := count.New(10)
-	if err := fromFile(file.Name(), 100, tk); err != nil {
+	if _, err := fromFile(file.Name(), 100, tk, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -83,6 +161,20 @@ func Test(t *testing.T) {
 	}
 }
 
+// writeTemp writes the content to a temporary file and returns it's name.
+func writeTemp(t *testing.T, pattern string, content string) string {
+	file, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
 func randWord(n int) string {
@@ -110,7 +202,7 @@ func Benchmark__length(b *testing.B) {
 
 			for i := 0; i < b.N; i++ {
 				tk := count.New(10)
-				fromFile(filePath, 2<<15-1, tk)
+				fromFile(filePath, 2<<15-1, tk, nil)
 			}
 		})
 	}
@@ -132,8 +224,296 @@ func Benchmark_buffer(b *testing.B) {
 
 			for i := 0; i < b.N; i++ {
 				tk := count.New(10)
-				fromFile("./assets/1000000lines.txt", size, tk)
+				fromFile("./assets/1000000lines.txt", size, tk, nil)
 			}
 		})
 	}
 }
+
+func Test_fromFile__partialBatch(t *testing.T) {
+	// NOTE: the last batch is shorter than the batch size
+	file := writeTemp(t, "txt", "the of  the")
+	defer os.Remove(file)
+
+	tk := count.New(10)
+	stats, err := fromFile(file, 4, tk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []count.Element{
+		{Key: "the", Count: 2},
+		{Key: "of", Count: 1},
+	}, tk.TopN(10))
+	assert.Equal(t, int64(11), stats.Bytes)
+}
+
+func Test_countAll(t *testing.T) {
+	file := writeTemp(t, "all", "The cat ran\nand the rat ran\n")
+	defer os.Remove(file)
+
+	// NOTE: small batches would split words
+	for _, batchSize := range []int64{1, 5, 1024} {
+		tk, err := countAll(file, batchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "ran", Count: 2},
+			{Key: "the", Count: 2},
+			{Key: "and", Count: 1},
+			{Key: "cat", Count: 1},
+			{Key: "rat", Count: 1},
+		}, tk.TopN(10), batchSize)
+	}
+}
+
+func Test_zipfTable(t *testing.T) {
+	file := writeTemp(t, "zipf", "The cat ran\nand the rat ran\n")
+	defer os.Remove(file)
+
+	// NOTE: small batches would split words
+	for _, batchSize := range []int64{1, 5, 1024} {
+		table, err := zipfTable(file, batchSize, count.New(10), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []zipf.Rank{
+			{Rank: 1, Word: "ran", Count: 2},
+			{Rank: 2, Word: "the", Count: 2},
+			{Rank: 3, Word: "and", Count: 1},
+			{Rank: 4, Word: "cat", Count: 1},
+			{Rank: 5, Word: "rat", Count: 1},
+		}, table, batchSize)
+	}
+}
+
+func Test_zipfTable__options(t *testing.T) {
+	file := writeTemp(t, "zipf", "id,text\n1,The Cats\n2,the cat\n")
+	defer os.Remove(file)
+
+	stem, err := normalize.New(normalize.Stem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: words are counted with the options of the run
+	table, err := zipfTable(file, 5, count.New(10), &options{
+		normalize: stem,
+		csv:       newCSVColumn("text", ','),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []zipf.Rank{
+		{Rank: 1, Word: "cat", Count: 2},
+		{Rank: 2, Word: "the", Count: 2},
+	}, table)
+
+	// NOTE: words that are counted already are not counted again
+	tk := count.NewUnfiltered(10)
+	tk.Insert("dog")
+	table, err = zipfTable(file, 5, tk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []zipf.Rank{
+		{Rank: 1, Word: "dog", Count: 1},
+	}, table)
+}
+
+func Test_analyzeFile(t *testing.T) {
+	file := writeTemp(t, "txt", "Héllo, wörld!\nv2 is 3.14 --\n")
+	defer os.Remove(file)
+
+	expected, err := analyzeFile(file, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(6), expected.Tokens)
+	assert.Equal(t, int64(28), expected.Chars)
+
+	// NOTE: small batches would split tokens and characters
+	for _, batchSize := range []int64{1, 2, 5} {
+		report, err := analyzeFile(file, batchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, report, batchSize)
+	}
+}
+
+func Test_fromFile__markup(t *testing.T) {
+	file := writeTemp(t, "html", `<html>
+<head><style>
+.with { color: red }
+</style></head>
+<body class="with">
+<p>The cat &amp; the dog</p>
+<script>
+var that = "with";
+</script>
+</body>
+</html>
+`)
+	defer os.Remove(file)
+
+	// NOTE: small batches would start inside of the style and script
+	for _, batchSize := range []int64{1, 16, 1024} {
+		tk := count.New(10)
+		if _, err := fromFile(file, batchSize, tk, &options{
+			markup: markup.HTML,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "the", Count: 2},
+		}, tk.TopN(10), batchSize)
+	}
+}
+
+func Test_fromFile__encoding(t *testing.T) {
+	// NOTE: utf-16le with byte order mark and a surrogate pair
+	utf16le := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune("the café,\nthe naïve\n😀 end")) {
+		utf16le = append(utf16le, byte(u), byte(u>>8))
+	}
+
+	testCases := []struct {
+		encoding charset.Encoding
+		content  []byte
+	}{
+		{charset.UTF16LE, utf16le},
+		{charset.Latin1, []byte("the caf\xe9,\nthe na\xefve\n\x80 end")},
+		{charset.CP1252, []byte("the caf\xe9,\nthe na\xefve\n\x81 end")},
+	}
+
+	for _, tc := range testCases {
+		file := writeTemp(t, "encoding", string(tc.content))
+		defer os.Remove(file)
+
+		// NOTE: odd batches would split code units, surrogate pairs and
+		// words if they were not line-aligned
+		for _, batchSize := range []int64{3, 7, 1024} {
+			tk := count.NewUnfiltered(10)
+			stats, err := fromFile(file, batchSize, tk, &options{
+				tokenizer: &tokenizer{unicode: true},
+				encoding:  tc.encoding,
+				lines:     true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, []count.Element{
+				{Key: "the", Count: 2},
+				{Key: "café", Count: 1},
+				{Key: "end", Count: 1},
+				{Key: "naïve", Count: 1},
+			}, tk.TopN(10), "%s: %d", tc.encoding, batchSize)
+			assert.Equal(t, uint64(5), stats.Tokens, "%s: %d", tc.encoding, batchSize)
+		}
+
+		// NOTE: batches split words, but they never split characters
+		for _, batchSize := range []int64{3, 7} {
+			tk := count.NewUnfiltered(10)
+			if _, err := fromFile(file, batchSize, tk, &options{
+				tokenizer: &tokenizer{unicode: true},
+				encoding:  tc.encoding,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range tk.TopN(10) {
+				assert.True(t, strings.Contains("the café, the naïve end", e.Key), "%s: %s", tc.encoding, e.Key)
+			}
+		}
+	}
+}
+
+func Test_fromFile__encodingMarkup(t *testing.T) {
+	file := writeTemp(t, "html", "<p>caf&eacute; na\xefve&nbsp;the</p>")
+	defer os.Remove(file)
+
+	// NOTE: the input is decoded once, before entities are
+	for _, enc := range []charset.Encoding{charset.Latin1, charset.CP1252} {
+		tk := count.NewUnfiltered(10)
+		if _, err := fromFile(file, 1024, tk, &options{
+			tokenizer: &tokenizer{unicode: true},
+			markup:    markup.HTML,
+			encoding:  enc,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "café", Count: 1},
+			{Key: "naïve", Count: 1},
+			{Key: "the", Count: 1},
+		}, tk.TopN(10), enc)
+	}
+}
+
+func Test_fromFile__bom(t *testing.T) {
+	p, err := newPattern(`(?m)^(\w+)`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		content string
+		opts    *options
+	}{
+		{"text", "the of", &options{}},
+		{"pattern", "the\nof\n", &options{pattern: p}},
+		{"csv", "text,id\nthe of,1\n", &options{csv: newCSVColumn("text", ',')}},
+		{"jsonl", "{\"m\":\"the\"}\n{\"m\":\"of\"}\n", &options{jsonl: newJSONLField("m")}},
+	}
+
+	for _, tc := range testCases {
+		file := writeTemp(t, tc.name, "\xef\xbb\xbf"+tc.content)
+		defer os.Remove(file)
+
+		tc.opts.encoding = charset.UTF8
+		tk := count.NewUnfiltered(10)
+		stats, err := fromFile(file, 1024, tk, tc.opts)
+		if err != nil {
+			t.Fatal(tc.name, err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "of", Count: 1},
+			{Key: "the", Count: 1},
+		}, tk.TopN(10), tc.name)
+		assert.Equal(t, int64(0), stats.MalformedLines, tc.name)
+	}
+}
+
+func Test_detectEncoding(t *testing.T) {
+	file := writeTemp(t, "encoding", "\xff\xfea\x00")
+	defer os.Remove(file)
+
+	enc, err := detectEncoding("", file)
+	assert.NoError(t, err)
+	assert.Equal(t, charset.UTF16LE, enc)
+
+	enc, err = detectEncoding("cp1252", file)
+	assert.NoError(t, err)
+	assert.Equal(t, charset.CP1252, enc)
+
+	_, err = detectEncoding("ebcdic", file)
+	assert.Error(t, err)
+
+	empty := writeTemp(t, "encoding", "")
+	defer os.Remove(empty)
+
+	enc, err = detectEncoding("", empty)
+	assert.NoError(t, err)
+	assert.Equal(t, charset.Encoding(""), enc)
+}
@@ -0,0 +1,109 @@
+package markup
+
+import (
+	"bytes"
+	"html"
+)
+
+const (
+	textState = iota
+	tagState
+	commentState
+	cdataState
+	rawState
+)
+
+// htmlState is a state of html and xml stripping.
+type htmlState struct {
+	state int
+	// quote is a quote of the current attribute value in a tag.
+	quote byte
+	// raw is a closing tag of the raw text element, e.g. "</script", once
+	// it's opening tag ends, content is skipped until the closing tag.
+	raw string
+}
+
+// rawElements are html elements which content is not text.
+var rawElements = []string{"script", "style"}
+
+// strip appends text of data to dst, with entities decoded.
+func (h *htmlState) strip(dst []byte, data []byte, isHTML bool) []byte {
+	start := len(dst)
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch h.state {
+		case textState:
+			if c != '<' {
+				dst = append(dst, c)
+				continue
+			}
+
+			switch {
+			case bytes.HasPrefix(data[i:], []byte("<!--")):
+				h.state = commentState
+				i += len("<!--") - 1
+			case bytes.HasPrefix(data[i:], []byte("<![CDATA[")):
+				h.state = cdataState
+				i += len("<![CDATA[") - 1
+			case isHTML && h.rawElement(data[i+1:]) != "":
+				h.state = tagState
+				h.raw = "</" + h.rawElement(data[i+1:])
+			default:
+				h.state = tagState
+			}
+			dst = append(dst, ' ')
+		case tagState:
+			switch {
+			case h.quote != 0:
+				if c == h.quote {
+					h.quote = 0
+				}
+			case c == '"' || c == '\'':
+				h.quote = c
+			case c == '>':
+				h.state = textState
+				if h.raw != "" {
+					h.state = rawState
+				}
+			}
+		case commentState:
+			if bytes.HasPrefix(data[i:], []byte("-->")) {
+				h.state = textState
+				i += len("-->") - 1
+			}
+		case cdataState:
+			if bytes.HasPrefix(data[i:], []byte("]]>")) {
+				h.state = textState
+				i += len("]]>") - 1
+				continue
+			}
+			dst = append(dst, c)
+		case rawState:
+			if len(data)-i >= len(h.raw) && bytes.EqualFold(data[i:i+len(h.raw)], []byte(h.raw)) {
+				h.state = tagState
+				i += len(h.raw) - 1
+				h.raw = ""
+			}
+		}
+	}
+
+	if bytes.IndexByte(dst[start:], '&') == -1 {
+		return dst
+	}
+	return append(dst[:start], html.UnescapeString(string(dst[start:]))...)
+}
+
+// rawElement returns name of the raw text element if data starts with it's
+// name, or empty string.
+func (h *htmlState) rawElement(data []byte) string {
+	for _, name := range rawElements {
+		if len(data) <= len(name) || !bytes.EqualFold(data[:len(name)], []byte(name)) {
+			continue
+		}
+		switch data[len(name)] {
+		case ' ', '\t', '\n', '\r', '/', '>':
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package markup
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var (
+	inlineCode = regexp.MustCompile("`+[^`]*`+")
+	// NOTE: links and images are replaced with their text
+	link          = regexp.MustCompile(`!?\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	autolink      = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9+.-]*:[^>\s]*>`)
+	url           = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+`)
+	linkReference = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*\S+`)
+)
+
+// markdown appends text of markdown lines of data to dst. Code blocks,
+// inline code, urls and html tags are skipped.
+func (s *Stripper) markdown(dst []byte, data []byte) []byte {
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i != -1 {
+			line, data = data[:i+1], data[i+1:]
+		} else {
+			data = nil
+		}
+
+		trimmed := bytes.TrimLeft(line, " ")
+		switch {
+		case s.fence != "":
+			if bytes.HasPrefix(trimmed, []byte(s.fence)) {
+				s.fence = ""
+			}
+			continue
+		case s.html.state != textState:
+			// NOTE: multiline html tag or comment
+		case bytes.HasPrefix(trimmed, []byte("```")):
+			s.fence = "```"
+			continue
+		case bytes.HasPrefix(trimmed, []byte("~~~")):
+			s.fence = "~~~"
+			continue
+		case linkReference.Match(line):
+			continue
+		default:
+			line = inlineCode.ReplaceAll(line, []byte(" "))
+			line = link.ReplaceAll(line, []byte("$1"))
+			line = autolink.ReplaceAll(line, []byte(" "))
+			line = url.ReplaceAll(line, []byte(" "))
+		}
+
+		dst = s.html.strip(dst, line, true)
+	}
+	return dst
+}
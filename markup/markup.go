@@ -0,0 +1,117 @@
+// Package markup strips markup of html, xml and markdown input, so only
+// the text is counted.
+package markup
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a name of an input format.
+type Format string
+
+// Supported formats.
+const (
+	Text     Format = "text"
+	HTML     Format = "html"
+	XML      Format = "xml"
+	Markdown Format = "markdown"
+)
+
+// Parse returns the format by it's name, empty name is detected by the
+// extension of the path.
+func Parse(name string, path string) (Format, error) {
+	switch Format(name) {
+	case "":
+		return Detect(path), nil
+	case Text, HTML, XML, Markdown:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown input format: `%s`", name)
+	}
+}
+
+// Detect returns the format of the path by it's extension, Text if it's
+// unknown.
+func Detect(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm", ".xhtml":
+		return HTML
+	case ".xml", ".svg", ".rss", ".atom":
+		return XML
+	case ".md", ".markdown":
+		return Markdown
+	default:
+		return Text
+	}
+}
+
+// Stripper strips markup of the input, that is fed to it in chunks of whole
+// lines. State is kept between chunks, so tags and code blocks can span
+// multiple lines.
+type Stripper struct {
+	format Format
+	html   htmlState
+	// fence is an opening fence of the current markdown code block.
+	fence string
+}
+
+// NewStripper returns a stripper of the format in the initial state.
+func NewStripper(format Format) *Stripper {
+	return &Stripper{
+		format: format,
+	}
+}
+
+// Strip appends text of data to dst and returns it. Tags are replaced with
+// spaces, so they separate words.
+func (s *Stripper) Strip(dst []byte, data []byte) []byte {
+	switch s.format {
+	case HTML, XML:
+		return s.html.strip(dst, data, s.format == HTML)
+	case Markdown:
+		return s.markdown(dst, data)
+	default:
+		return append(dst, data...)
+	}
+}
+
+// Safe returns true if the stripper is in the initial state, so input can
+// be split at the current position.
+func (s *Stripper) Safe() bool {
+	return s.html.state == textState && s.fence == ""
+}
+
+// Boundaries returns the first line start after every multiple of batchSize
+// where input of the format can be split, i.e. it's not in a tag or a code
+// block.
+func Boundaries(r io.Reader, format Format, batchSize int64) ([]int64, error) {
+	boundaries := []int64{}
+	s := NewStripper(format)
+	next := batchSize
+
+	pos := int64(0)
+	buff := []byte{}
+	reader := bufio.NewReaderSize(r, 64<<10)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			buff = s.Strip(buff[:0], line)
+			pos += int64(len(line))
+			if pos >= next && line[len(line)-1] == '\n' && s.Safe() {
+				boundaries = append(boundaries, pos)
+				next = (pos/batchSize + 1) * batchSize
+			}
+		}
+
+		if err == io.EOF {
+			return boundaries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
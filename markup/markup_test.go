@@ -0,0 +1,139 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Strip(t *testing.T) {
+	testCases := []struct {
+		name     string
+		format   Format
+		input    string
+		expected string
+	}{
+		{
+			name:     "text",
+			format:   Text,
+			input:    "<b>the</b> cat",
+			expected: "<b>the</b> cat",
+		},
+		{
+			name:     "tags",
+			format:   HTML,
+			input:    `<div class="main"><a href="/x">the</a>cat</div>`,
+			expected: "  the cat ",
+		},
+		{
+			name:     "quoted attributes",
+			format:   HTML,
+			input:    `<img alt="a > b" title='c > d'>dog`,
+			expected: " dog",
+		},
+		{
+			name:     "entities",
+			format:   HTML,
+			input:    "fish &amp; chips&nbsp;&lt;b&gt;",
+			expected: "fish & chips <b>",
+		},
+		{
+			name:     "script and style",
+			format:   HTML,
+			input:    "a<SCRIPT type=\"x\">var div = '</div>';\n</Script >b<style>p { color: red }</style>c<scripts>d",
+			expected: "a b c d",
+		},
+		{
+			name:     "comment",
+			format:   HTML,
+			input:    "a<!-- <p>hidden</p>\n -->b",
+			expected: "a b",
+		},
+		{
+			name:     "xml",
+			format:   XML,
+			input:    `<?xml version="1.0"?><note><body><![CDATA[the <cat>]]></body><script>of</script></note>`,
+			expected: "    the <cat>  of  ",
+		},
+		{
+			name:   "markdown",
+			format: Markdown,
+			input: strings.Join([]string{
+				"# The title",
+				"Read [the docs](https://example.com/docs) and ![logo](logo.png).",
+				"Run `go test` or see <https://example.com>, https://example.org/x.",
+				"```go",
+				"func main() {}",
+				"```",
+				"  ~~~",
+				"hidden",
+				"~~~",
+				"[docs]: https://example.com",
+				"A <span class=\"x\">span</span> &amp; [ref][docs].",
+				"",
+			}, "\n"),
+			expected: strings.Join([]string{
+				"# The title",
+				"Read the docs and logo.",
+				"Run   or see  ,  ",
+				"A  span  & ref.",
+				"",
+			}, "\n"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(NewStripper(tc.format).Strip(nil, []byte(tc.input))))
+		})
+	}
+}
+
+func Test_Stripper__chunks(t *testing.T) {
+	s := NewStripper(HTML)
+
+	text := s.Strip(nil, []byte("a<p\nclass=\"x\">b<script>\n"))
+	assert.False(t, s.Safe())
+
+	text = s.Strip(text, []byte("var x;\n"))
+	assert.False(t, s.Safe())
+
+	text = s.Strip(text, []byte("</script>c\n"))
+	assert.True(t, s.Safe())
+
+	assert.Equal(t, "a b c\n", string(text))
+}
+
+func Test_Boundaries(t *testing.T) {
+	input := "<p>\none</p>\n<script>\nx\n</script>\n<p>two</p>\n"
+
+	boundaries, err := Boundaries(strings.NewReader(input), HTML, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: there are no boundaries inside of the script
+	assert.Equal(t, []int64{4, 12, 33, 44}, boundaries)
+
+	boundaries, err = Boundaries(strings.NewReader("a\n```\nb\n```\nc\n"), Markdown, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int64{2, 12, 14}, boundaries)
+}
+
+func Test_Parse(t *testing.T) {
+	format, err := Parse("", "docs/index.MD")
+	assert.NoError(t, err)
+	assert.Equal(t, Markdown, format)
+
+	format, err = Parse("html", "notes.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, HTML, format)
+
+	assert.Equal(t, Text, Detect("notes.txt"))
+	assert.Equal(t, XML, Detect("feed.rss"))
+
+	_, err = Parse("pdf", "")
+	assert.Error(t, err)
+}
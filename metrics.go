@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/metrics"
+)
+
+// registry holds pipeline metrics of the process.
+var registry = metrics.NewRegistry()
+
+var (
+	bytesProcessed   = registry.NewCounter("words_bytes_processed_total", "Number of processed bytes.")
+	batchesProcessed = registry.NewCounter("words_batches_processed_total", "Number of processed batches.")
+	tokensSeen       = registry.NewCounter("words_tokens_total", "Number of tokens seen.")
+	tokensMatched    = registry.NewCounter("words_tokens_matched_total", "Number of tokens matched by the vocabulary.")
+	batchDuration    = registry.NewHistogram(
+		"words_batch_duration_seconds",
+		"Time spent processing a batch.",
+		[]float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+	)
+)
+
+const (
+	topWordsMetric = "words_top_word_count"
+	topWordsHelp   = "Number of occurrences of the most frequent words."
+)
+
+// topSamples returns top n words of the stream as metric samples.
+func topSamples(tk *count.Stream, n int) []metrics.Sample {
+	top := tk.TopN(n)
+	samples := make([]metrics.Sample, 0, len(top))
+	for _, e := range top {
+		samples = append(samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "word", Value: e.Key}},
+			Value:  float64(e.Count),
+		})
+	}
+	return samples
+}
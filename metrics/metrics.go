@@ -0,0 +1,245 @@
+// Package metrics implements a minimal set of metrics exposed in Prometheus
+// text format.
+//
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type collector interface {
+	expose(w io.Writer) error
+}
+
+// Registry holds metrics and exposes them.
+type Registry struct {
+	guard      sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.guard.Lock()
+	r.collectors = append(r.collectors, c)
+	r.guard.Unlock()
+}
+
+// NewCounter registers and returns a new counter.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	r.register(c)
+	return c
+}
+
+// NewHistogram registers and returns a new histogram with given upper bounds
+// of the buckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	bb := make([]float64, len(buckets))
+	copy(bb, buckets)
+	sort.Float64s(bb)
+
+	h := &Histogram{
+		name:    name,
+		help:    help,
+		buckets: bb,
+		counts:  make([]uint64, len(bb)),
+	}
+	r.register(h)
+	return h
+}
+
+// NewGaugeFunc registers a gauge that's values are returned by fn on every
+// exposition.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() []Sample) {
+	r.register(&gaugeFunc{name: name, help: help, fn: fn})
+}
+
+// Expose writes all registered metrics to w.
+func (r *Registry) Expose(w io.Writer) error {
+	r.guard.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.guard.Unlock()
+
+	for _, c := range collectors {
+		if err := c.expose(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	if err := r.Expose(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ContentType is a content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4"
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	name  string
+	help  string
+	value uint64
+}
+
+// Add increases the counter by n.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.value, n)
+}
+
+// Inc increases the counter by one.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) expose(w io.Writer) error {
+	if err := writeHeader(w, c.name, c.help, "counter"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %d\n", c.name, c.Value())
+	return err
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	guard  sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds a single observation to the histogram.
+func (h *Histogram) Observe(v float64) {
+	h.guard.Lock()
+	defer h.guard.Unlock()
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *Histogram) expose(w io.Writer) error {
+	h.guard.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	count, sum := h.count, h.sum
+	h.guard.Unlock()
+
+	if err := writeHeader(w, h.name, h.help, "histogram"); err != nil {
+		return err
+	}
+	for i, upper := range h.buckets {
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(upper), counts[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, count); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s_sum %s\n", h.name, formatFloat(sum)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s_count %d\n", h.name, count)
+	return err
+}
+
+// Label is a name-value pair that identifies a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a metric.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+type gaugeFunc struct {
+	name string
+	help string
+	fn   func() []Sample
+}
+
+func (g *gaugeFunc) expose(w io.Writer) error {
+	return WriteGauge(w, g.name, g.help, g.fn())
+}
+
+// WriteGauge writes gauge samples to w.
+func WriteGauge(w io.Writer, name, help string, samples []Sample) error {
+	if err := writeHeader(w, name, help, "gauge"); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(s.Labels), formatFloat(s.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHeader(w io.Writer, name, help, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, kind)
+	return err
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", l.Name, labelEscaper.Replace(l.Value)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
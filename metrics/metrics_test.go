@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Registry(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("test_total", "Test counter.")
+	c.Inc()
+	c.Add(2)
+
+	h := r.NewHistogram("test_seconds", "Test histogram.", []float64{1, 0.5})
+	h.Observe(0.1)
+	h.Observe(0.7)
+	h.Observe(2)
+
+	r.NewGaugeFunc("test_gauge", "Test gauge.", func() []Sample {
+		return []Sample{
+			{Labels: []Label{{Name: "word", Value: `"quoted"`}}, Value: 3},
+		}
+	})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, r.Expose(buf))
+	assert.Equal(t, `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total 3
+# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{le="0.5"} 1
+test_seconds_bucket{le="1"} 2
+test_seconds_bucket{le="+Inf"} 3
+test_seconds_sum 2.8
+test_seconds_count 3
+# HELP test_gauge Test gauge.
+# TYPE test_gauge gauge
+test_gauge{word="\"quoted\""} 3
+`, buf.String())
+}
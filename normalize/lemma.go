@@ -0,0 +1,102 @@
+package normalize
+
+// Lemmatize returns dictionary form of a lowercase word. Irregular forms are
+// looked up in a table, other words are stemmed.
+func Lemmatize(word string) string {
+	if lemma, ok := lemmas[word]; ok {
+		return lemma
+	}
+	return Porter2(word)
+}
+
+// lemmas are irregular forms of common english words.
+var lemmas = map[string]string{
+	"am":         "be",
+	"is":         "be",
+	"are":        "be",
+	"was":        "be",
+	"were":       "be",
+	"been":       "be",
+	"being":      "be",
+	"has":        "have",
+	"had":        "have",
+	"having":     "have",
+	"does":       "do",
+	"did":        "do",
+	"done":       "do",
+	"doing":      "do",
+	"says":       "say",
+	"said":       "say",
+	"goes":       "go",
+	"went":       "go",
+	"gone":       "go",
+	"made":       "make",
+	"took":       "take",
+	"taken":      "take",
+	"came":       "come",
+	"saw":        "see",
+	"seen":       "see",
+	"knew":       "know",
+	"known":      "know",
+	"got":        "get",
+	"gotten":     "get",
+	"gave":       "give",
+	"given":      "give",
+	"thought":    "think",
+	"told":       "tell",
+	"found":      "find",
+	"felt":       "feel",
+	"left":       "leave",
+	"brought":    "bring",
+	"began":      "begin",
+	"begun":      "begin",
+	"kept":       "keep",
+	"held":       "hold",
+	"wrote":      "write",
+	"written":    "write",
+	"stood":      "stand",
+	"heard":      "hear",
+	"meant":      "mean",
+	"met":        "meet",
+	"ran":        "run",
+	"paid":       "pay",
+	"sat":        "sit",
+	"spoke":      "speak",
+	"spoken":     "speak",
+	"led":        "lead",
+	"grew":       "grow",
+	"grown":      "grow",
+	"lost":       "lose",
+	"fell":       "fall",
+	"fallen":     "fall",
+	"sent":       "send",
+	"built":      "build",
+	"understood": "understand",
+	"drew":       "draw",
+	"drawn":      "draw",
+	"broke":      "break",
+	"broken":     "break",
+	"spent":      "spend",
+	"rose":       "rise",
+	"risen":      "rise",
+	"drove":      "drive",
+	"driven":     "drive",
+	"bought":     "buy",
+	"wore":       "wear",
+	"worn":       "wear",
+	"chose":      "choose",
+	"chosen":     "choose",
+	"ate":        "eat",
+	"eaten":      "eat",
+	"children":   "child",
+	"men":        "man",
+	"women":      "woman",
+	"feet":       "foot",
+	"teeth":      "tooth",
+	"mice":       "mouse",
+	"geese":      "goose",
+	"better":     "good",
+	"best":       "good",
+	"worse":      "bad",
+	"worst":      "bad",
+}
@@ -0,0 +1,54 @@
+// Package normalize maps inflected forms of words to a common form, so they
+// are counted together.
+package normalize
+
+import (
+	"fmt"
+)
+
+// Func returns normalized form of a lowercase word.
+type Func func(word string) string
+
+// Mode is a name of a normalization.
+type Mode string
+
+// Supported modes.
+const (
+	None  Mode = "none"
+	Stem  Mode = "stem"
+	Lemma Mode = "lemma"
+)
+
+// New returns normalization function of the mode, nil for None.
+func New(mode Mode) (Func, error) {
+	switch mode {
+	case None, "":
+		return nil, nil
+	case Stem:
+		return Porter2, nil
+	case Lemma:
+		return Lemmatize, nil
+	default:
+		return nil, fmt.Errorf("unknown normalization: `%s`", mode)
+	}
+}
+
+// Canonical returns fn that maps normalized forms of the vocabulary words
+// back to the words, e.g. "peopl" to "people", so normalized words can be
+// matched against the vocabulary.
+func Canonical(fn Func, vocabulary []string) Func {
+	canonical := make(map[string]string, len(vocabulary))
+	for _, word := range vocabulary {
+		if _, ok := canonical[fn(word)]; !ok {
+			canonical[fn(word)] = word
+		}
+	}
+
+	return func(word string) string {
+		normalized := fn(word)
+		if c, ok := canonical[normalized]; ok {
+			return c
+		}
+		return normalized
+	}
+}
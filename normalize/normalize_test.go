@@ -0,0 +1,42 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lemmatize(t *testing.T) {
+	for word, lemma := range map[string]string{
+		"said":     "say",
+		"says":     "say",
+		"saying":   "say",
+		"was":      "be",
+		"children": "child",
+		"knights":  "knight",
+	} {
+		assert.Equal(t, lemma, Lemmatize(word), word)
+	}
+}
+
+func Test_Canonical(t *testing.T) {
+	fn := Canonical(Porter2, []string{"people", "say"})
+
+	assert.Equal(t, "people", fn("people"))
+	assert.Equal(t, "people", fn("peoples"))
+	assert.Equal(t, "say", fn("says"))
+	assert.Equal(t, "knight", fn("knights"))
+}
+
+func Test_New(t *testing.T) {
+	fn, err := New(None)
+	assert.NoError(t, err)
+	assert.Nil(t, fn)
+
+	fn, err = New(Stem)
+	assert.NoError(t, err)
+	assert.Equal(t, "knight", fn("knights"))
+
+	_, err = New("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,367 @@
+package normalize
+
+import (
+	"strings"
+)
+
+// Porter2 returns stem of a lowercase english word.
+//
+// https://snowballstem.org/algorithms/english/stemmer.html
+func Porter2(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	if stem, ok := exceptions1[word]; ok {
+		return stem
+	}
+
+	w := []byte(strings.TrimPrefix(word, "'"))
+	markY(w)
+
+	r1, r2 := regions(w)
+
+	w = step0(w)
+	w = step1a(w)
+
+	if exceptions2[string(w)] {
+		return restoreY(w)
+	}
+
+	w = step1b(w, r1)
+	w = step1c(w)
+	w = step2(w, r1)
+	w = step3(w, r1, r2)
+	w = step4(w, r2)
+	w = step5(w, r1, r2)
+
+	return restoreY(w)
+}
+
+var exceptions1 = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+var exceptions2 = map[string]bool{
+	"inning":  true,
+	"outing":  true,
+	"canning": true,
+	"herring": true,
+	"earring": true,
+	"proceed": true,
+	"exceed":  true,
+	"succeed": true,
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	default:
+		return false
+	}
+}
+
+func isDouble(w []byte) bool {
+	if len(w) < 2 || w[len(w)-1] != w[len(w)-2] {
+		return false
+	}
+	switch w[len(w)-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	default:
+		return false
+	}
+}
+
+func isLiEnding(c byte) bool {
+	switch c {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	default:
+		return false
+	}
+}
+
+// markY replaces initial y and y after a vowel with Y, so it's treated as a
+// consonant.
+func markY(w []byte) {
+	for i, c := range w {
+		if c == 'y' && (i == 0 || isVowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+}
+
+func restoreY(w []byte) string {
+	for i, c := range w {
+		if c == 'Y' {
+			w[i] = 'y'
+		}
+	}
+	return string(w)
+}
+
+// regions returns starts of R1 and R2: R1 is a region after the first
+// non-vowel following a vowel, R2 is the same region within R1.
+func regions(w []byte) (int, int) {
+	r1 := len(w)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(w), prefix) {
+			r1 = len(prefix)
+			break
+		}
+	}
+	if r1 == len(w) {
+		r1 = regionAfter(w, 0)
+	}
+	return r1, regionAfter(w, r1)
+}
+
+func regionAfter(w []byte, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+// isShortSyllable returns true if w ends with a short syllable: a vowel
+// followed by a non-vowel other than w, x or Y and preceded by a non-vowel,
+// or a vowel at the beginning of the word followed by a non-vowel.
+func isShortSyllable(w []byte) bool {
+	l := len(w)
+	switch {
+	case l == 2:
+		return isVowel(w[0]) && !isVowel(w[1])
+	case l > 2:
+		c := w[l-1]
+		return !isVowel(w[l-3]) && isVowel(w[l-2]) && !isVowel(c) &&
+			c != 'w' && c != 'x' && c != 'Y'
+	default:
+		return false
+	}
+}
+
+func isShort(w []byte, r1 int) bool {
+	return r1 >= len(w) && isShortSyllable(w)
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+// longestSuffix returns the longest of suffixes the word ends with.
+func longestSuffix(w []byte, suffixes ...string) string {
+	longest := ""
+	for _, s := range suffixes {
+		if len(s) > len(longest) && hasSuffix(w, s) {
+			longest = s
+		}
+	}
+	return longest
+}
+
+func replaceSuffix(w []byte, suffix, replacement string) []byte {
+	return append(w[:len(w)-len(suffix)], replacement...)
+}
+
+func containsVowel(w []byte) bool {
+	for _, c := range w {
+		if isVowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func step0(w []byte) []byte {
+	if s := longestSuffix(w, "'s'", "'s", "'"); s != "" {
+		return w[:len(w)-len(s)]
+	}
+	return w
+}
+
+func step1a(w []byte) []byte {
+	switch s := longestSuffix(w, "sses", "ied", "ies", "us", "ss", "s"); s {
+	case "sses":
+		return replaceSuffix(w, s, "ss")
+	case "ied", "ies":
+		if len(w) > 4 {
+			return replaceSuffix(w, s, "i")
+		}
+		return replaceSuffix(w, s, "ie")
+	case "s":
+		// NOTE: delete if the preceding part contains a vowel not
+		// immediately before the s
+		if len(w) > 2 && containsVowel(w[:len(w)-2]) {
+			return w[:len(w)-1]
+		}
+	}
+	return w
+}
+
+func step1b(w []byte, r1 int) []byte {
+	switch s := longestSuffix(w, "eed", "eedly", "ed", "edly", "ing", "ingly"); s {
+	case "eed", "eedly":
+		if len(w)-len(s) >= r1 {
+			return replaceSuffix(w, s, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		stem := w[:len(w)-len(s)]
+		if !containsVowel(stem) {
+			return w
+		}
+		switch {
+		case hasSuffix(stem, "at"), hasSuffix(stem, "bl"), hasSuffix(stem, "iz"):
+			return append(stem, 'e')
+		case isDouble(stem):
+			return stem[:len(stem)-1]
+		case isShort(stem, r1):
+			return append(stem, 'e')
+		default:
+			return stem
+		}
+	}
+	return w
+}
+
+func step1c(w []byte) []byte {
+	l := len(w)
+	if l > 2 && (w[l-1] == 'y' || w[l-1] == 'Y') && !isVowel(w[l-2]) {
+		w[l-1] = 'i'
+	}
+	return w
+}
+
+var step2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+func step2(w []byte, r1 int) []byte {
+	s := longestSuffixOf(w, step2Suffixes)
+	if s == "" || len(w)-len(s) < r1 {
+		return w
+	}
+
+	stem := w[:len(w)-len(s)]
+	switch s {
+	case "ogi":
+		if !hasSuffix(stem, "l") {
+			return w
+		}
+	case "li":
+		if len(stem) == 0 || !isLiEnding(stem[len(stem)-1]) {
+			return w
+		}
+	}
+	return replaceSuffix(w, s, step2Suffixes[s])
+}
+
+var step3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+func step3(w []byte, r1, r2 int) []byte {
+	s := longestSuffixOf(w, step3Suffixes)
+	if s == "" || len(w)-len(s) < r1 {
+		return w
+	}
+	if s == "ative" && len(w)-len(s) < r2 {
+		return w
+	}
+	return replaceSuffix(w, s, step3Suffixes[s])
+}
+
+var step4Suffixes = map[string]string{
+	"al": "", "ance": "", "ence": "", "er": "", "ic": "", "able": "",
+	"ible": "", "ant": "", "ement": "", "ment": "", "ent": "", "ism": "",
+	"ate": "", "iti": "", "ous": "", "ive": "", "ize": "", "ion": "",
+}
+
+func step4(w []byte, r2 int) []byte {
+	s := longestSuffixOf(w, step4Suffixes)
+	if s == "" || len(w)-len(s) < r2 {
+		return w
+	}
+
+	stem := w[:len(w)-len(s)]
+	if s == "ion" && !hasSuffix(stem, "s") && !hasSuffix(stem, "t") {
+		return w
+	}
+	return stem
+}
+
+func step5(w []byte, r1, r2 int) []byte {
+	l := len(w)
+	switch {
+	case hasSuffix(w, "e"):
+		if l-1 >= r2 || l-1 >= r1 && !isShortSyllable(w[:l-1]) {
+			return w[:l-1]
+		}
+	case hasSuffix(w, "l"):
+		if l-1 >= r2 && hasSuffix(w[:l-1], "l") {
+			return w[:l-1]
+		}
+	}
+	return w
+}
+
+func longestSuffixOf(w []byte, suffixes map[string]string) string {
+	longest := ""
+	for s := range suffixes {
+		if len(s) > len(longest) && hasSuffix(w, s) {
+			longest = s
+		}
+	}
+	return longest
+}
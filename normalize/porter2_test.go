@@ -0,0 +1,120 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// NOTE: samples from the published Porter2 vocabulary
+// https://snowballstem.org/algorithms/english/stemmer.html
+var porter2Samples = map[string]string{
+	"consign":       "consign",
+	"consigned":     "consign",
+	"consigning":    "consign",
+	"consignment":   "consign",
+	"consist":       "consist",
+	"consisted":     "consist",
+	"consistency":   "consist",
+	"consistent":    "consist",
+	"consistently":  "consist",
+	"consisting":    "consist",
+	"consists":      "consist",
+	"consolation":   "consol",
+	"consolations":  "consol",
+	"consolatory":   "consolatori",
+	"console":       "consol",
+	"consoled":      "consol",
+	"consoles":      "consol",
+	"consolidate":   "consolid",
+	"consolidated":  "consolid",
+	"consolidating": "consolid",
+	"consoling":     "consol",
+	"consolingly":   "consol",
+	"consols":       "consol",
+	"consonant":     "conson",
+	"consort":       "consort",
+	"consorted":     "consort",
+	"consorting":    "consort",
+	"conspicuous":   "conspicu",
+	"conspicuously": "conspicu",
+	"conspiracy":    "conspiraci",
+	"conspirator":   "conspir",
+	"conspirators":  "conspir",
+	"conspire":      "conspir",
+	"conspired":     "conspir",
+	"conspiring":    "conspir",
+	"constable":     "constabl",
+	"constables":    "constabl",
+	"constance":     "constanc",
+	"constancy":     "constanc",
+	"constant":      "constant",
+	"knack":         "knack",
+	"knackeries":    "knackeri",
+	"knacks":        "knack",
+	"knag":          "knag",
+	"knave":         "knave",
+	"knaves":        "knave",
+	"knavish":       "knavish",
+	"kneaded":       "knead",
+	"kneading":      "knead",
+	"knee":          "knee",
+	"kneel":         "kneel",
+	"kneeled":       "kneel",
+	"kneeling":      "kneel",
+	"kneels":        "kneel",
+	"knees":         "knee",
+	"knell":         "knell",
+	"knelt":         "knelt",
+	"knew":          "knew",
+	"knick":         "knick",
+	"knif":          "knif",
+	"knife":         "knife",
+	"knight":        "knight",
+	"knightly":      "knight",
+	"knights":       "knight",
+	"knit":          "knit",
+	"knits":         "knit",
+	"knitted":       "knit",
+	"knitting":      "knit",
+	"knives":        "knive",
+	"knob":          "knob",
+	"knobs":         "knob",
+	"knock":         "knock",
+	"knocked":       "knock",
+	"knocker":       "knocker",
+	"knockers":      "knocker",
+	"knocking":      "knock",
+	"knocks":        "knock",
+	"knopp":         "knopp",
+	"knot":          "knot",
+	"knots":         "knot",
+}
+
+func Test_Porter2(t *testing.T) {
+	for word, stem := range porter2Samples {
+		assert.Equal(t, stem, Porter2(word), word)
+	}
+}
+
+func Test_Porter2__exceptions(t *testing.T) {
+	for word, stem := range map[string]string{
+		"skies":      "sky",
+		"dying":      "die",
+		"news":       "news",
+		"inning":     "inning",
+		"proceed":    "proceed",
+		"generously": "generous",
+		"communism":  "communism",
+		"cries":      "cri",
+		"ties":       "tie",
+		"says":       "say",
+		"saying":     "say",
+		"hoped":      "hope",
+		"hopping":    "hop",
+		"a":          "a",
+		"by":         "by",
+	} {
+		assert.Equal(t, stem, Porter2(word), word)
+	}
+}
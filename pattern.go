@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// pattern extracts tokens matched by a regular expression instead of words,
+// e.g. status codes or ip addresses of log lines.
+type pattern struct {
+	re *regexp.Regexp
+	// group is an index of the capturing group to count, 0 is the whole
+	// match.
+	group int
+}
+
+func newPattern(expr string, group int) (*pattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %s", err)
+	}
+
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf("pattern `%s` has no group %d", expr, group)
+	}
+
+	return &pattern{
+		re:    re,
+		group: group,
+	}, nil
+}
+
+// tokenize calls fn for every match of the pattern in the batch, it's like
+// tokenize, but matches are not lowercased and not truncated to maxLen, their
+// length is up to the pattern. Empty matches and matches where the group
+// doesn't participate are skipped.
+func (p *pattern) tokenize(batch []byte, _ int, fn func(word []byte, start, end int)) {
+	for _, m := range p.re.FindAllSubmatchIndex(batch, -1) {
+		start, end := m[2*p.group], m[2*p.group+1]
+		if start == end {
+			continue
+		}
+
+		fn(batch[start:end], start, end)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_pattern(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		group    int
+		input    string
+		expected []string
+	}{
+		{
+			name:     "whole match",
+			expr:     `\b\d{3}\b`,
+			input:    "GET / 200 12ms\nGET /a 404 1ms",
+			expected: []string{"200", "404"},
+		},
+		{
+			name:     "group",
+			expr:     `user=(\w+)`,
+			group:    1,
+			input:    "user=Alice ok user=bob",
+			expected: []string{"Alice", "bob"},
+		},
+		{
+			name:     "optional group",
+			expr:     `id(=(\d+))?`,
+			group:    2,
+			input:    "id=1 id id=2",
+			expected: []string{"1", "2"},
+		},
+		{
+			name:     "empty matches",
+			expr:     `\d*`,
+			input:    "a1b22",
+			expected: []string{"1", "22"},
+		},
+		{
+			name:  "long matches",
+			expr:  `GET (\S+)`,
+			group: 1,
+			input: "GET /api/v1/users/" + strings.Repeat("a", 80) + "/x\nGET /api/v1/users/" + strings.Repeat("a", 80) + "/y",
+			expected: []string{
+				"/api/v1/users/" + strings.Repeat("a", 80) + "/x",
+				"/api/v1/users/" + strings.Repeat("a", 80) + "/y",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := newPattern(tc.expr, tc.group)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			words := []string{}
+			p.tokenize([]byte(tc.input), maxWordLen, func(word []byte, start, end int) {
+				words = append(words, string(word))
+			})
+			assert.Equal(t, tc.expected, words)
+		})
+	}
+}
+
+func Test_newPattern__errors(t *testing.T) {
+	_, err := newPattern(`(`, 0)
+	assert.Error(t, err)
+
+	_, err = newPattern(`(\d+)`, 2)
+	assert.Error(t, err)
+}
+
+func Test_fromFile__pattern(t *testing.T) {
+	lines := []string{
+		"10.0.0.1 - GET /index.html 200",
+		"10.0.0.2 - GET /missing 404",
+		"10.0.0.1 - POST /login 200",
+		"192.168.100.200 - GET /" + strings.Repeat("a", 100) + " 500",
+		"10.0.0.1 - GET /index.html 304",
+	}
+	content := strings.Join(lines, "\n")
+	file := writeTemp(t, "pattern", content)
+	defer os.Remove(file)
+
+	p, err := newPattern(`(?m)^(\d+\.\d+\.\d+\.\d+) `, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: small batches would split addresses if batches were not
+	// line-aligned, the fourth line is longer than a batch
+	for _, batchSize := range []int64{5, 16, 1024} {
+		tk := count.NewUnfiltered(10)
+		stats, err := fromFile(file, batchSize, tk, &options{pattern: p})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "10.0.0.1", Count: 3},
+			{Key: "10.0.0.2", Count: 1},
+			{Key: "192.168.100.200", Count: 1},
+		}, tk.TopN(10), batchSize)
+		assert.Equal(t, int64(len(content)), stats.Bytes, batchSize)
+	}
+}
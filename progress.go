@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progress tracks number of processed bytes, it's updated by batch workers.
+type progress struct {
+	total   int64
+	done    int64
+	started time.Time
+}
+
+func newProgress() *progress {
+	return &progress{
+		started: time.Now(),
+	}
+}
+
+// setTotal sets total number of bytes to process. It's safe to call on nil.
+func (p *progress) setTotal(n int64) {
+	if p == nil {
+		return
+	}
+	atomic.StoreInt64(&p.total, n)
+}
+
+// add marks n bytes as processed. It's safe to call on nil.
+func (p *progress) add(n int64) {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.done, n)
+}
+
+// format returns a progress line as if elapsed time has passed since start.
+func (p *progress) format(elapsed time.Duration) string {
+	total := atomic.LoadInt64(&p.total)
+	done := atomic.LoadInt64(&p.done)
+
+	percent := 0.0
+	if total > 0 {
+		percent = float64(done) / float64(total) * 100
+	}
+
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(done) / elapsed.Seconds()
+	}
+
+	eta := "-"
+	if rate > 0 && total >= done {
+		eta = time.Duration(float64(total-done) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%.1f MB / %.1f MB (%.1f%%), %.1f MB/s, ETA %s",
+		float64(done)/1e6, float64(total)/1e6, percent, rate/1e6, eta)
+}
+
+// start reports progress to w in background, returned function stops
+// reporting and waits for the final line to be written.
+func (p *progress) start(w *os.File) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.report(w, stop)
+		close(done)
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// report writes progress to w until stop is closed. If w is a terminal the
+// line is redrawn in place, otherwise it's logged periodically.
+func (p *progress) report(w *os.File, stop <-chan struct{}) {
+	if !isTerminal(w) {
+		p.log(w, 5*time.Second, stop)
+		return
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// NOTE: \x1b[K clears the rest of the line
+			fmt.Fprintf(w, "\r%s\x1b[K", p.format(time.Since(p.started)))
+		case <-stop:
+			fmt.Fprintf(w, "\r%s\x1b[K\n", p.format(time.Since(p.started)))
+			return
+		}
+	}
+}
+
+func (p *progress) log(w io.Writer, interval time.Duration, stop <-chan struct{}) {
+	logger := log.New(w, "", log.LstdFlags)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			logger.Println(p.format(time.Since(p.started)))
+		case <-stop:
+			logger.Println(p.format(time.Since(p.started)))
+			return
+		}
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
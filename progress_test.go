@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_progress_format(t *testing.T) {
+	p := newProgress()
+	p.setTotal(100e6)
+	p.add(25e6)
+
+	assert.Equal(t, "25.0 MB / 100.0 MB (25.0%), 5.0 MB/s, ETA 15s", p.format(5*time.Second))
+	assert.Equal(t, "25.0 MB / 100.0 MB (25.0%), 0.0 MB/s, ETA -", p.format(0))
+}
+
+func Test_progress__nil(t *testing.T) {
+	var p *progress
+	p.setTotal(10)
+	p.add(10)
+}
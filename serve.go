@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ngalaiko/words/count"
+	"github.com/ngalaiko/words/metrics"
+)
+
+// serve runs words as an http service.
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	topN := flags.Int("n", 10, "default top N words")
+	maxBodySize := flags.Int64("max-body-size", 10<<20, "max request body size in bytes")
+	metricsTopN := flags.Int("metrics-top-n", 0, "export top N words as metrics")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	s := newServer(*topN, *maxBodySize, *metricsTopN)
+
+	log.Printf("listening on %s", *addr)
+	return http.ListenAndServe(*addr, s)
+}
+
+type server struct {
+	n           int
+	maxBodySize int64
+	metricsTopN int
+	mux         *http.ServeMux
+
+	// NOTE: guard protects stream from being replaced by reset while words
+	// are inserted, stream itself is safe for concurrent use.
+	guard  sync.RWMutex
+	stream *count.Stream
+}
+
+func newServer(n int, maxBodySize int64, metricsTopN int) *server {
+	s := &server{
+		n:           n,
+		maxBodySize: maxBodySize,
+		metricsTopN: metricsTopN,
+		mux:         http.NewServeMux(),
+		stream:      count.New(n),
+	}
+
+	s.mux.HandleFunc("/count", s.handleCount)
+	s.mux.HandleFunc("/top", s.handleTop)
+	s.mux.HandleFunc("/reset", s.handleReset)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
+
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleCount counts words in the request body, adds them to the
+// accumulated counts and returns top N words of the body.
+func (s *server) handleCount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := s.parseN(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	switch {
+	case errors.As(err, new(*http.MaxBytesError)):
+		http.Error(w, fmt.Sprintf("body is larger than %d bytes", s.maxBodySize), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, fmt.Sprintf("failed to read body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	tk := count.New(n)
+	processBatch(body, maxWordLen, tk)
+
+	s.guard.RLock()
+	s.stream.Merge(tk)
+	s.guard.RUnlock()
+
+	writeJSON(w, tk.TopN(n))
+}
+
+// handleTop returns top N words of all counted requests.
+func (s *server) handleTop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := s.parseN(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.guard.RLock()
+	top := s.stream.TopN(n)
+	s.guard.RUnlock()
+
+	writeJSON(w, top)
+}
+
+// handleReset drops all accumulated counts.
+func (s *server) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.guard.Lock()
+	s.stream = count.New(s.n)
+	s.guard.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleMetrics exposes pipeline metrics and, if enabled, top words of all
+// counted requests.
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := registry.Expose(w); err != nil {
+		log.Printf("failed to write metrics: %s", err)
+		return
+	}
+
+	if s.metricsTopN <= 0 {
+		return
+	}
+
+	s.guard.RLock()
+	samples := topSamples(s.stream, s.metricsTopN)
+	s.guard.RUnlock()
+
+	if err := metrics.WriteGauge(w, topWordsMetric, topWordsHelp, samples); err != nil {
+		log.Printf("failed to write metrics: %s", err)
+	}
+}
+
+// parseN returns `n` query parameter, or default value if it's not set.
+func (s *server) parseN(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("n")
+	if value == "" {
+		return s.n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid n: `%s`", value)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %s", err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_server(t *testing.T) {
+	ts := httptest.NewServer(newServer(10, 1<<10, 0))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, []count.Element{
+		{Key: "the", Count: 2},
+		{Key: "of", Count: 1},
+	}, postCount(t, ts.URL+"/count", "The cat of the dog"))
+
+	assert.Equal(t, []count.Element{
+		{Key: "of", Count: 2},
+	}, postCount(t, ts.URL+"/count?n=1", "of of the"))
+
+	assert.Equal(t, []count.Element{
+		{Key: "of", Count: 3},
+		{Key: "the", Count: 3},
+	}, getTop(t, ts.URL+"/top?n=2"))
+
+	// NOTE: words are not truncated, so prefixes of longer words are not
+	// counted
+	assert.Equal(t, []count.Element{
+		{Key: "about", Count: 1},
+		{Key: "people", Count: 1},
+		{Key: "think", Count: 1},
+		{Key: "which", Count: 1},
+		{Key: "would", Count: 1},
+	}, postCount(t, ts.URL+"/count", "which people would think about without whatever"))
+
+	resp, err = http.Post(ts.URL+"/reset", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.Empty(t, getTop(t, ts.URL+"/top"))
+}
+
+func Test_server__errors(t *testing.T) {
+	ts := httptest.NewServer(newServer(10, 16, 0))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/count", "text/plain", strings.NewReader(strings.Repeat("the ", 10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Get(ts.URL + "/top?n=zero")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_server__readError(t *testing.T) {
+	s := newServer(10, 1<<10, 0)
+
+	// NOTE: only a body over the limit is too large, other read errors are
+	// client errors
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/count", iotest.TimeoutReader(strings.NewReader("the of"))))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_server__metrics(t *testing.T) {
+	ts := httptest.NewServer(newServer(10, 1<<10, 1))
+	defer ts.Close()
+
+	postCount(t, ts.URL+"/count", "the of the")
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(body), "# TYPE words_tokens_total counter\n")
+	assert.Contains(t, string(body), "# TYPE words_batch_duration_seconds histogram\n")
+	assert.Contains(t, string(body), "words_top_word_count{word=\"the\"} 2\n")
+	assert.NotContains(t, string(body), "words_top_word_count{word=\"of\"}")
+}
+
+func postCount(t *testing.T, url string, body string) []count.Element {
+	resp, err := http.Post(url, "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	res := []count.Element{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func getTop(t *testing.T, url string) []count.Element {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	res := []count.Element{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ngalaiko/words/count"
+)
+
+// sourceCategories are categories of words of go source code, in the order
+// they are printed.
+var sourceCategories = []string{"comments", "strings", "identifiers"}
+
+// countSource prints the most common words of comments, string literals and
+// identifiers of go source files.
+func countSource(args []string) error {
+	flags := flag.NewFlagSet("source", flag.ExitOnError)
+	n := flags.Int("n", 10, "number of words to print for each category")
+	asJSON := flags.Bool("json", false, "print words as json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: words source [-n=10] [-json] path...")
+	}
+
+	files, err := goFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	streams, errs := sourceWords(files)
+	for _, err := range errs {
+		log.Print(err)
+	}
+
+	top := make(map[string][]count.Element, len(streams))
+	for category, tk := range streams {
+		top[category] = tk.TopN(*n)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(top)
+	}
+
+	for i, category := range sourceCategories {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", category)
+		for _, e := range top[category] {
+			fmt.Printf("%d: %s\n", e.Count, e.Key)
+		}
+	}
+	return nil
+}
+
+// goFiles returns go files of the paths, directories are walked
+// recursively, skipping vendor, testdata and hidden directories.
+func goFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		if err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				name := info.Name()
+				if p != path && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if p == path || strings.HasSuffix(p, ".go") {
+				files = append(files, p)
+			}
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to read `%s`: %s", path, err)
+		}
+	}
+	return files, nil
+}
+
+// sourceWords counts words of every category of the files concurrently.
+// Files that can't be read or scanned don't stop counting, errors of them
+// are returned sorted, words of the scanned part of a file are counted.
+func sourceWords(files []string) (map[string]*count.Stream, []error) {
+	streams := make(map[string]*count.Stream, len(sourceCategories))
+	for _, category := range sourceCategories {
+		streams[category] = count.NewUnfiltered(0)
+	}
+
+	guard := &sync.Mutex{}
+	errs := []error{}
+
+	wg := &errgroup.Group{}
+	// NOTE: every goroutine holds an open file
+	wg.SetLimit(runtime.GOMAXPROCS(0))
+	for _, file := range files {
+		file := file
+		wg.Go(func() error {
+			src, err := ioutil.ReadFile(file)
+			if err != nil {
+				err = fmt.Errorf("failed to read `%s`: %s", file, err)
+			} else {
+				err = scanSource(file, src, streams)
+			}
+
+			if err != nil {
+				guard.Lock()
+				errs = append(errs, err)
+				guard.Unlock()
+			}
+			return nil
+		})
+	}
+	// NOTE: goroutines don't fail, errors of files are collected instead
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return streams, errs
+}
+
+// scanSource counts words of the go source in streams of their categories.
+func scanSource(filename string, src []byte, streams map[string]*count.Stream) error {
+	insert := func(tk *count.Stream) func(word []byte, start, end int) {
+		return func(word []byte, start, end int) {
+			tk.Insert(string(word))
+		}
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(filename, -1, len(src))
+
+	var errs scanner.ErrorList
+	s := &scanner.Scanner{}
+	s.Init(file, src, func(pos token.Position, msg string) {
+		errs.Add(pos, msg)
+	}, scanner.ScanComments)
+
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		switch tok {
+		case token.COMMENT:
+			tokenize([]byte(lit), maxWordLen, insert(streams["comments"]))
+		case token.STRING:
+			if unquoted, err := strconv.Unquote(lit); err == nil {
+				lit = unquoted
+			}
+			tokenize([]byte(lit), maxWordLen, insert(streams["strings"]))
+		case token.IDENT:
+			for _, word := range splitIdentifier(lit) {
+				streams["identifiers"].Insert(word)
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// splitIdentifier splits camelCase and snake_case identifier into lowercase
+// words, e.g. "parseHTTPRequest" into "parse", "http" and "request". Digits
+// are a part of the previous word, parts without letters are skipped.
+func splitIdentifier(ident string) []string {
+	words := []string{}
+	word := []byte{}
+	letters := false
+	flush := func() {
+		if letters {
+			words = append(words, strings.ToLower(string(word)))
+		}
+		word = word[:0]
+		letters = false
+	}
+
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		switch {
+		case c == '_':
+			flush()
+			continue
+		case isUpper(c) && i > 0:
+			prev := ident[i-1]
+			// NOTE: "aB" starts a word at B, "ABc" starts a word at B
+			if !isUpper(prev) && prev != '_' || i+1 < len(ident) && isLower(ident[i+1]) {
+				flush()
+			}
+		}
+
+		word = append(word, c)
+		if isUpper(c) || isLower(c) {
+			letters = true
+		}
+	}
+	flush()
+
+	return words
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_splitIdentifier(t *testing.T) {
+	testCases := []struct {
+		ident    string
+		expected []string
+	}{
+		{"count", []string{"count"}},
+		{"fromFile", []string{"from", "file"}},
+		{"parseHTTPRequest", []string{"parse", "http", "request"}},
+		{"ServeHTTP", []string{"serve", "http"}},
+		{"error_code", []string{"error", "code"}},
+		{"MAX_WORD_LEN", []string{"max", "word", "len"}},
+		{"_private", []string{"private"}},
+		{"utf8Decoder", []string{"utf8", "decoder"}},
+		{"v2", []string{"v2"}},
+		{"_", []string{}},
+		{"x_1", []string{"x"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ident, func(t *testing.T) {
+			assert.Equal(t, tc.expected, splitIdentifier(tc.ident))
+		})
+	}
+}
+
+func Test_scanSource(t *testing.T) {
+	src := `package main
+
+// countWords counts the words.
+func countWords(text string) int {
+	/* the block comment */
+	return len(text) + len("the \"quoted\" text") + len(` + "`raw string`" + `) + 'x'
+}
+`
+
+	streams := map[string]*count.Stream{}
+	for _, category := range sourceCategories {
+		streams[category] = count.NewUnfiltered(0)
+	}
+	if err := scanSource("main.go", []byte(src), streams); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []count.Element{
+		{Key: "the", Count: 2},
+		{Key: "block", Count: 1},
+		{Key: "comment", Count: 1},
+		{Key: "counts", Count: 1},
+		{Key: "countwords", Count: 1},
+		{Key: "words", Count: 1},
+	}, streams["comments"].TopN(10))
+
+	assert.Equal(t, []count.Element{
+		{Key: "quoted", Count: 1},
+		{Key: "raw", Count: 1},
+		{Key: "string", Count: 1},
+		{Key: "text", Count: 1},
+		{Key: "the", Count: 1},
+	}, streams["strings"].TopN(10))
+
+	assert.Equal(t, []count.Element{
+		{Key: "len", Count: 3},
+		{Key: "text", Count: 2},
+		{Key: "count", Count: 1},
+		{Key: "int", Count: 1},
+		{Key: "main", Count: 1},
+		{Key: "string", Count: 1},
+		{Key: "words", Count: 1},
+	}, streams["identifiers"].TopN(10))
+}
+
+func Test_scanSource__errors(t *testing.T) {
+	streams := map[string]*count.Stream{}
+	for _, category := range sourceCategories {
+		streams[category] = count.NewUnfiltered(0)
+	}
+
+	assert.Error(t, scanSource("main.go", []byte("package main\nvar s = \"unterminated\n"), streams))
+}
+
+func Test_sourceWords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"main.go":             "package main\n\n// the comment\n",
+		"broken.go":           "package main\nvar s = \"unterminated\n",
+		"testdata/invalid.go": "package testdata\nvar s = \"unterminated\n",
+		"vendor/lib/lib.go":   "package lib\n\n// the vendored comment\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := goFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "broken.go"),
+		filepath.Join(dir, "main.go"),
+	}, paths)
+
+	// NOTE: an error in one file doesn't stop counting of the others
+	streams, errs := sourceWords(append(paths, filepath.Join(dir, "missing.go")))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, []count.Element{
+		{Key: "comment", Count: 1},
+		{Key: "the", Count: 1},
+	}, streams["comments"].TopN(10))
+}
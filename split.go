@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ngalaiko/words/charset"
+)
+
+// splitter returns the first offset at or after offset where input of the
+// size can be split into batches. Zero and the size are always valid
+// offsets. Splitters don't look past limit, they return limit if there is no
+// offset to split at before it.
+type splitter func(r io.ReaderAt, offset int64, limit int64, size int64) (int64, error)
+
+// splitBytes splits input at any offset.
+func splitBytes(_ io.ReaderAt, offset int64, limit int64, size int64) (int64, error) {
+	return clamp(offset, limit, size), nil
+}
+
+// splitLines splits input at line starts, so batches consist of whole lines.
+func splitLines(r io.ReaderAt, offset int64, limit int64, size int64) (int64, error) {
+	return splitUnitLines(charset.UTF8)(r, offset, limit, size)
+}
+
+// splitAt returns a splitter that splits input at the sorted boundaries, no
+// batch starts before start.
+func splitAt(boundaries []int64, start int64) splitter {
+	return func(_ io.ReaderAt, offset int64, limit int64, size int64) (int64, error) {
+		if offset <= start {
+			return clamp(start, limit, size), nil
+		}
+		i := sort.Search(len(boundaries), func(i int) bool { return boundaries[i] >= offset })
+		if i == len(boundaries) {
+			return clamp(size, limit, size), nil
+		}
+		return clamp(boundaries[i], limit, size), nil
+	}
+}
+
+// splitUnits returns a splitter that doesn't split code units and surrogate
+// pairs of the encoding.
+func splitUnits(enc charset.Encoding) splitter {
+	unitSize := int64(charset.UnitSize(enc))
+	return func(r io.ReaderAt, offset int64, limit int64, size int64) (int64, error) {
+		offset = (offset + unitSize - 1) / unitSize * unitSize
+		if offset >= size || offset >= limit {
+			return clamp(offset, limit, size), nil
+		}
+
+		unit := make([]byte, unitSize)
+		if _, err := r.ReadAt(unit, offset); err != nil && err != io.EOF {
+			return 0, err
+		}
+		if charset.IsContinuation(enc, unit) {
+			offset += unitSize
+		}
+
+		return clamp(offset, limit, size), nil
+	}
+}
+
+// splitUnitLines returns a splitter that splits input of the encoding at
+// line starts, like splitLines, but newlines are whole code units.
+func splitUnitLines(enc charset.Encoding) splitter {
+	unitSize := int64(charset.UnitSize(enc))
+	return func(r io.ReaderAt, offset int64, limit int64, size int64) (int64, error) {
+		offset = (offset + unitSize - 1) / unitSize * unitSize
+		if offset <= 0 {
+			return 0, nil
+		}
+
+		limit = clamp(limit, size, size)
+
+		// NOTE: a line starts at offset if the previous unit is a newline
+		buff := make([]byte, 4<<10)
+		for pos := offset - unitSize; pos < limit; pos += int64(len(buff)) {
+			chunk := buff
+			if rest := limit - pos; rest < int64(len(chunk)) {
+				chunk = chunk[:rest]
+			}
+
+			n, err := r.ReadAt(chunk, pos)
+			if err != nil && err != io.EOF {
+				return 0, err
+			}
+
+			if unitSize == 1 {
+				if i := bytes.IndexByte(chunk[:n], '\n'); i != -1 {
+					return pos + int64(i) + 1, nil
+				}
+			} else {
+				for i := int64(0); i+unitSize <= int64(n); i += unitSize {
+					if charset.IsNewline(enc, chunk[i:i+unitSize]) {
+						return pos + i + unitSize, nil
+					}
+				}
+			}
+
+			if err == io.EOF {
+				break
+			}
+		}
+
+		return limit, nil
+	}
+}
+
+// clamp returns offset, but not more than limit and size.
+func clamp(offset int64, limit int64, size int64) int64 {
+	if offset > limit {
+		offset = limit
+	}
+	if offset > size {
+		offset = size
+	}
+	return offset
+}
+
+// batchAt returns start and end of the i-th batch of input of the size
+// split by split. The batch is empty if a previous batch covers it, e.g.
+// when a line is longer than batchSize.
+func batchAt(r io.ReaderAt, split splitter, i int64, batchSize int64, size int64) (int64, int64, error) {
+	start, end := batchSize*i, batchSize*(i+1)
+	if end > size {
+		end = size
+	}
+
+	// NOTE: if there is no offset to split at inside of the batch, it's
+	// covered by a previous batch, so it's not needed to look further
+	start, err := split(r, start, end, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start >= end {
+		return end, end, nil
+	}
+
+	end, err = split(r, end, size, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
+
+// readSplitBatchesAt is like readBatchesAt, but batches are split by split,
+// so they are longer or shorter than batchSize. Empty batches are skipped.
+// If batchSize is not positive, it's picked by the file size.
+func readSplitBatchesAt(filepath string, batchSize int64, p *progress, split splitter, fn func(offset int64, batch []byte) error) error {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to read `%s`: %s", filepath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat `%s`: %s", filepath, err)
+	}
+	size := info.Size()
+
+	if batchSize <= 0 {
+		batchSize = autoBatchSize(size, runtime.GOMAXPROCS(0))
+	}
+
+	p.setTotal(size)
+
+	all := (size + batchSize - 1) / batchSize
+	wg := &errgroup.Group{}
+	// NOTE: every worker holds a batch, so the limit bounds memory
+	wg.SetLimit(runtime.GOMAXPROCS(0))
+	for i := int64(0); i < all; i++ {
+		i := i
+		// NOTE: read concurrently and process in batch
+		wg.Go(func() error {
+			start, end, err := batchAt(file, split, i, batchSize, size)
+			if err != nil {
+				return err
+			}
+			if start == end {
+				return nil
+			}
+
+			buff := make([]byte, end-start)
+
+			// NOTE: the file can be truncated while it's read
+			off, err := file.ReadAt(buff, start)
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			if err := fn(start, buff[:off]); err != nil {
+				return err
+			}
+			p.add(int64(off))
+
+			return nil
+		})
+	}
+
+	return wg.Wait()
+}
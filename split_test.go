@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/charset"
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_splitLines(t *testing.T) {
+	input := "short\n" + strings.Repeat("x", 10000) + "\n\nend"
+	r := strings.NewReader(input)
+	size := int64(len(input))
+
+	testCases := []struct {
+		offset   int64
+		expected int64
+	}{
+		{0, 0},
+		{1, 6},
+		{6, 6},
+		{7, 10007},
+		{10006, 10007},
+		{10007, 10007},
+		{10008, 10008},
+		{10009, size},
+		{size, size},
+	}
+
+	for _, tc := range testCases {
+		offset, err := splitLines(r, tc.offset, size, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tc.expected, offset, tc.offset)
+	}
+}
+
+func Test_splitUnitLines(t *testing.T) {
+	// NOTE: "a\n" is followed by U+0A0A, it's bytes are newlines, but it's
+	// not a newline
+	input := "a\x00\n\x00\n\x0a\n\x00b\x00"
+	r := strings.NewReader(input)
+	size := int64(len(input))
+
+	testCases := []struct {
+		offset   int64
+		expected int64
+	}{
+		{0, 0},
+		{1, 4},
+		{3, 4},
+		{4, 4},
+		{5, 8},
+		{8, 8},
+		{9, size},
+	}
+
+	split := splitUnitLines(charset.UTF16LE)
+	for _, tc := range testCases {
+		offset, err := split(r, tc.offset, size, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tc.expected, offset, tc.offset)
+	}
+}
+
+func Test_batchAt(t *testing.T) {
+	input := "a\n" + strings.Repeat("b", 20) + "\nc\nd\n"
+	r := strings.NewReader(input)
+	size := int64(len(input))
+
+	spans := [][2]int64{}
+	for i := int64(0); i < (size+4)/5; i++ {
+		start, end, err := batchAt(r, splitLines, i, 5, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans = append(spans, [2]int64{start, end})
+	}
+
+	// NOTE: the first batch ends after the long line, batches inside of it
+	// are empty
+	assert.Equal(t, [][2]int64{
+		{0, 23},
+		{10, 10},
+		{15, 15},
+		{20, 20},
+		{23, 25},
+		{25, 27},
+	}, spans)
+}
+
+// countingReader counts bytes read from it.
+type countingReader struct {
+	r    io.ReaderAt
+	read int64
+}
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read += int64(n)
+	return n, err
+}
+
+func Test_batchAt__longLine(t *testing.T) {
+	input := "a\n" + strings.Repeat("b", 1<<20) + "\nc\n"
+	r := &countingReader{r: strings.NewReader(input)}
+	size := int64(len(input))
+
+	batchSize := int64(1 << 10)
+	for i := int64(0); i < (size+batchSize-1)/batchSize; i++ {
+		if _, _, err := batchAt(r, splitLines, i, batchSize, size); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// NOTE: batches inside of the line don't look for it's end, so the
+	// line is read once by the batch it starts in and once by the batches
+	// inside of it
+	assert.True(t, r.read <= 2*size+batchSize, "read %d bytes of %d", r.read, size)
+}
+
+func Test_readSplitBatchesAt__lines(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	lines := []string{}
+	for i := 0; i < 200; i++ {
+		lines = append(lines, strings.Repeat(string('a'+rune(i%26)), rnd.Intn(50)))
+	}
+	content := strings.Join(lines, "\n")
+
+	file := writeTemp(t, "lines", content)
+	defer os.Remove(file)
+
+	// NOTE: most of the lines are longer than the smallest batches
+	for _, batchSize := range []int64{1, 3, 16, 100, 1 << 20} {
+		guard := &sync.Mutex{}
+		batches := map[int64][]byte{}
+		if err := readLinesAt(file, batchSize, nil, func(offset int64, batch []byte) error {
+			guard.Lock()
+			batches[offset] = batch
+			guard.Unlock()
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		joined := []byte{}
+		for offset := int64(0); offset < int64(len(content)); {
+			batch, ok := batches[offset]
+			if !assert.True(t, ok, "batch size %d: no batch at %d", batchSize, offset) {
+				break
+			}
+
+			if offset > 0 {
+				assert.Equal(t, byte('\n'), content[offset-1], "batch size %d: batch at %d starts inside a line", batchSize, offset)
+			}
+
+			joined = append(joined, batch...)
+			offset += int64(len(batch))
+		}
+		assert.True(t, bytes.Equal([]byte(content), joined), batchSize)
+	}
+}
+
+func Test_fromFile__lines(t *testing.T) {
+	file := writeTemp(t, "lines", "the of\nthe and\nwith the\n")
+	defer os.Remove(file)
+
+	// NOTE: batches would split words if they were not line-aligned
+	for _, batchSize := range []int64{1, 2, 5} {
+		tk := count.New(10)
+		if _, err := fromFile(file, batchSize, tk, &options{lines: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []count.Element{
+			{Key: "the", Count: 3},
+			{Key: "and", Count: 1},
+			{Key: "of", Count: 1},
+			{Key: "with", Count: 1},
+		}, tk.TopN(10), batchSize)
+	}
+}
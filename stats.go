@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats describes a single run.
+type Stats struct {
+	// Bytes is a number of processed input bytes.
+	Bytes int64
+	// Batches is a number of processed batches.
+	Batches int64
+	// BatchSize is a size of batches the input is read in.
+	BatchSize int64
+	// Tokens is a number of all seen tokens.
+	Tokens uint64
+	// MatchedTokens is a number of tokens matched by the vocabulary.
+	MatchedTokens uint64
+	// DistinctWords is a number of distinct counted words.
+	DistinctWords int
+	// MalformedLines is a number of skipped json lines that failed to
+	// parse.
+	MalformedLines int64
+	// LongestWord is the longest seen token.
+	LongestWord string
+	// Elapsed is a duration of the run.
+	Elapsed time.Duration
+}
+
+func (s *Stats) addToken(token []byte, matched bool) {
+	s.Tokens++
+	if matched {
+		s.MatchedTokens++
+	}
+	if len(token) > len(s.LongestWord) {
+		s.LongestWord = string(token)
+	}
+}
+
+// merge adds stats of a batch to s.
+func (s *Stats) merge(other *Stats) {
+	s.Bytes += other.Bytes
+	s.Batches += other.Batches
+	s.Tokens += other.Tokens
+	s.MatchedTokens += other.MatchedTokens
+
+	// NOTE: batches are merged in random order, so pick the smallest word
+	// of the same length to keep the result stable
+	if len(other.LongestWord) > len(s.LongestWord) ||
+		len(other.LongestWord) == len(s.LongestWord) && other.LongestWord < s.LongestWord {
+		s.LongestWord = other.LongestWord
+	}
+}
+
+// RejectionRate returns a fraction of tokens filtered out by the vocabulary.
+func (s *Stats) RejectionRate() float64 {
+	if s.Tokens == 0 {
+		return 0
+	}
+	return float64(s.Tokens-s.MatchedTokens) / float64(s.Tokens)
+}
+
+// Throughput returns number of processed megabytes per second.
+func (s *Stats) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / 1e6 / s.Elapsed.Seconds()
+}
+
+func (s *Stats) String() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "input bytes:\t%d\n", s.Bytes)
+	fmt.Fprintf(w, "batches:\t%d\n", s.Batches)
+	fmt.Fprintf(w, "batch size:\t%d\n", s.BatchSize)
+	fmt.Fprintf(w, "tokens:\t%d\n", s.Tokens)
+	fmt.Fprintf(w, "matched tokens:\t%d\n", s.MatchedTokens)
+	fmt.Fprintf(w, "rejection rate:\t%.2f%%\n", s.RejectionRate()*100)
+	fmt.Fprintf(w, "distinct words:\t%d\n", s.DistinctWords)
+	if s.MalformedLines > 0 {
+		fmt.Fprintf(w, "malformed lines:\t%d\n", s.MalformedLines)
+	}
+	fmt.Fprintf(w, "longest word:\t%s\n", s.LongestWord)
+	fmt.Fprintf(w, "elapsed:\t%s\n", s.Elapsed)
+	fmt.Fprintf(w, "throughput:\t%.2f MB/s\n", s.Throughput())
+	w.Flush()
+	return buf.String()
+}
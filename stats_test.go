@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+const statsCorpus = "The cat sat on the mat.\nIt is what it is, isn't it?\n"
+
+func Test_fromFile__stats(t *testing.T) {
+	file := writeTemp(t, "stats", statsCorpus)
+	defer os.Remove(file)
+
+	stats, err := fromFile(file, 1024, count.New(10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(len(statsCorpus)), stats.Bytes)
+	assert.Equal(t, int64(1), stats.Batches)
+	assert.Equal(t, uint64(14), stats.Tokens)
+	assert.Equal(t, uint64(7), stats.MatchedTokens)
+	assert.Equal(t, 4, stats.DistinctWords)
+	assert.Equal(t, "what", stats.LongestWord)
+	assert.InDelta(t, 0.5, stats.RejectionRate(), 1e-9)
+
+	stats, err = fromFile(file, 10, count.New(10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(len(statsCorpus)), stats.Bytes)
+	assert.Equal(t, int64(6), stats.Batches)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ngalaiko/words/tfidf"
+)
+
+// rankTFIDF prints words with the highest tf-idf of every file and of all
+// files together.
+func rankTFIDF(args []string) error {
+	flags := flag.NewFlagSet("tfidf", flag.ExitOnError)
+	n := flags.Int("n", 10, "number of words to print for each file")
+	batchSize := byteSizeVar(flags, "batch-size", batchSizeUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: words tfidf [-n=10] [-batch-size=auto] file...")
+	}
+
+	docs := make([]tfidf.Document, 0, flags.NArg())
+	for _, filepath := range flags.Args() {
+		// NOTE: count all words, the most common ones occur in every document
+		// anyway
+		tk, err := countAll(filepath, int64(*batchSize))
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, tfidf.Document{
+			Name:  filepath,
+			Words: tk.TopN(tk.Len()),
+		})
+	}
+
+	ix := tfidf.NewIndex(docs)
+	for i, doc := range docs {
+		fmt.Printf("%s:\n", doc.Name)
+		if err := printTerms(os.Stdout, ix.Top(i, *n)); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("all files:")
+	return printTerms(os.Stdout, ix.TopCollection(*n))
+}
+
+func printTerms(w io.Writer, terms []tfidf.Term) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "word\tcount\tdf\ttf-idf")
+	for _, t := range terms {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.6f\n", t.Word, t.Count, t.DocumentFrequency, t.Score)
+	}
+	return tw.Flush()
+}
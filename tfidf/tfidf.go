@@ -0,0 +1,128 @@
+// Package tfidf ranks words of documents in a collection by term
+// frequency–inverse document frequency.
+//
+// https://en.wikipedia.org/wiki/Tf%E2%80%93idf
+package tfidf
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ngalaiko/words/count"
+)
+
+// Document is a named set of word counts.
+type Document struct {
+	Name  string
+	Words []count.Element
+}
+
+// Term is a scored word.
+type Term struct {
+	Word string
+	// Count is a number of occurrences of the word in a document or in the
+	// whole collection.
+	Count uint64
+	// DocumentFrequency is a number of documents containing the word.
+	DocumentFrequency int
+	// Score is tf-idf of the word.
+	Score float64
+}
+
+// Index holds document frequencies of words in a collection.
+type Index struct {
+	docs []Document
+	df   map[string]int
+}
+
+// NewIndex returns an index of the documents.
+func NewIndex(docs []Document) *Index {
+	df := map[string]int{}
+	for _, doc := range docs {
+		for _, w := range doc.Words {
+			if w.Count > 0 {
+				df[w.Key]++
+			}
+		}
+	}
+
+	return &Index{
+		docs: docs,
+		df:   df,
+	}
+}
+
+// IDF returns inverse document frequency of the word: ln(N / df).
+func (ix *Index) IDF(word string) float64 {
+	df := ix.df[word]
+	if df == 0 {
+		return 0
+	}
+	return math.Log(float64(len(ix.docs)) / float64(df))
+}
+
+// Top returns n words of the i-th document with the highest tf-idf, where
+// tf is a number of occurrences of the word divided by the number of all
+// words in the document. Words that occur in every document are skipped.
+func (ix *Index) Top(i int, n int) []Term {
+	doc := ix.docs[i]
+
+	var total uint64
+	for _, w := range doc.Words {
+		total += w.Count
+	}
+
+	terms := make([]Term, 0, len(doc.Words))
+	for _, w := range doc.Words {
+		score := float64(w.Count) / float64(total) * ix.IDF(w.Key)
+		if score <= 0 {
+			continue
+		}
+		terms = append(terms, Term{
+			Word:              w.Key,
+			Count:             w.Count,
+			DocumentFrequency: ix.df[w.Key],
+			Score:             score,
+		})
+	}
+
+	return top(terms, n)
+}
+
+// TopCollection returns n words with the highest tf-idf summed over all
+// documents of the collection.
+func (ix *Index) TopCollection(n int) []Term {
+	byWord := map[string]*Term{}
+	for i := range ix.docs {
+		for _, t := range ix.Top(i, len(ix.docs[i].Words)) {
+			if existing, ok := byWord[t.Word]; ok {
+				existing.Count += t.Count
+				existing.Score += t.Score
+				continue
+			}
+			t := t
+			byWord[t.Word] = &t
+		}
+	}
+
+	terms := make([]Term, 0, len(byWord))
+	for _, t := range byWord {
+		terms = append(terms, *t)
+	}
+
+	return top(terms, n)
+}
+
+func top(terms []Term, n int) []Term {
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Score != terms[j].Score {
+			return terms[i].Score > terms[j].Score
+		}
+		return terms[i].Word < terms[j].Word
+	})
+
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
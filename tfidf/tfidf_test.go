@@ -0,0 +1,89 @@
+package tfidf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func testIndex() *Index {
+	return NewIndex([]Document{
+		{
+			Name: "the cat sat",
+			Words: []count.Element{
+				{Key: "the", Count: 1},
+				{Key: "cat", Count: 1},
+				{Key: "sat", Count: 1},
+			},
+		},
+		{
+			Name: "the dog sat",
+			Words: []count.Element{
+				{Key: "the", Count: 1},
+				{Key: "dog", Count: 1},
+				{Key: "sat", Count: 1},
+			},
+		},
+		{
+			Name: "the cat cat ran",
+			Words: []count.Element{
+				{Key: "the", Count: 1},
+				{Key: "cat", Count: 2},
+				{Key: "ran", Count: 1},
+			},
+		},
+	})
+}
+
+func Test_IDF(t *testing.T) {
+	ix := testIndex()
+
+	assert.Equal(t, 0.0, ix.IDF("the"))
+	assert.InDelta(t, math.Log(1.5), ix.IDF("cat"), 1e-9)
+	assert.InDelta(t, math.Log(3), ix.IDF("dog"), 1e-9)
+	assert.Equal(t, 0.0, ix.IDF("unknown"))
+}
+
+func Test_Top(t *testing.T) {
+	ix := testIndex()
+
+	assertTerms(t, []Term{
+		{Word: "cat", Count: 1, DocumentFrequency: 2, Score: math.Log(1.5) / 3},
+		{Word: "sat", Count: 1, DocumentFrequency: 2, Score: math.Log(1.5) / 3},
+	}, ix.Top(0, 10))
+
+	assertTerms(t, []Term{
+		{Word: "dog", Count: 1, DocumentFrequency: 1, Score: math.Log(3) / 3},
+	}, ix.Top(1, 1))
+
+	assertTerms(t, []Term{
+		{Word: "ran", Count: 1, DocumentFrequency: 1, Score: math.Log(3) / 4},
+		{Word: "cat", Count: 2, DocumentFrequency: 2, Score: math.Log(1.5) / 2},
+	}, ix.Top(2, 10))
+}
+
+func Test_TopCollection(t *testing.T) {
+	ix := testIndex()
+
+	assertTerms(t, []Term{
+		{Word: "dog", Count: 1, DocumentFrequency: 1, Score: math.Log(3) / 3},
+		{Word: "cat", Count: 3, DocumentFrequency: 2, Score: math.Log(1.5)/3 + math.Log(1.5)/2},
+		{Word: "ran", Count: 1, DocumentFrequency: 1, Score: math.Log(3) / 4},
+		{Word: "sat", Count: 2, DocumentFrequency: 2, Score: 2 * math.Log(1.5) / 3},
+	}, ix.TopCollection(10))
+}
+
+func assertTerms(t *testing.T, expected, actual []Term) {
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.Equal(t, expected[i].Word, actual[i].Word)
+		assert.Equal(t, expected[i].Count, actual[i].Count)
+		assert.Equal(t, expected[i].DocumentFrequency, actual[i].DocumentFrequency)
+		assert.InDelta(t, expected[i].Score, actual[i].Score, 1e-9)
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenize splits the batch into lowercase words of ascii letters and calls
+// fn for every word. Words longer than maxLen are truncated, start and end
+// are offsets of the whole word in the batch. The word buffer is reused
+// between calls.
+func tokenize(batch []byte, maxLen int, fn func(word []byte, start, end int)) {
+	wordBuf := make([]byte, maxLen)
+	wordPos := 0
+	wordStart := -1
+
+	// TODO: there is a case when a word is splitted by a buffered read
+	// NOTE: I don't care
+
+	for i, c := range batch {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			c += 32
+			fallthrough
+		case c >= 'a' && c <= 'z':
+			if wordStart == -1 {
+				wordStart = i
+			}
+			if wordPos == maxLen {
+				continue
+			}
+			wordBuf[wordPos] = c
+			wordPos++
+		default:
+			if wordPos == 0 {
+				continue
+			}
+
+			fn(wordBuf[:wordPos], wordStart, i)
+
+			wordPos = 0
+			wordStart = -1
+		}
+	}
+
+	if wordPos > 0 && wordPos <= maxLen {
+		fn(wordBuf[:wordPos], wordStart, len(batch))
+	}
+}
+
+// tokenizer configures how words are split, nil or zero tokenizer is the
+// same as tokenize.
+type tokenizer struct {
+	// apostrophes keeps apostrophes between letters, so "don't" is a single
+	// word instead of "don" and "t".
+	apostrophes bool
+	// contractions expands common contractions, so "don't" is counted as
+	// "do" and "not". It implies apostrophes.
+	contractions bool
+	// hyphens keeps hyphens between word characters, so "well-known" is a
+	// single word.
+	hyphens bool
+	// underscores makes underscores word characters, so "error_code" is a
+	// single word.
+	underscores bool
+	// digits makes digits word characters, so "v2" and "covid19" are words.
+	digits bool
+	// dots keeps dots between word characters, so "os.path" is a single
+	// word, but the dot at the end of a sentence is not.
+	dots bool
+	// keepCase keeps the original case of words instead of lowercasing
+	// them.
+	keepCase bool
+	// unicode splits utf-8 batches into words of unicode letters instead of
+	// ascii ones.
+	unicode bool
+}
+
+// tokenize is like tokenize, but follows the configuration.
+func (t *tokenizer) tokenize(batch []byte, maxLen int, fn func(word []byte, start, end int)) {
+	if t == nil || *t == (tokenizer{}) {
+		tokenize(batch, maxLen, fn)
+		return
+	}
+
+	emit := fn
+	if t.contractions {
+		emit = func(word []byte, start, end int) {
+			for _, w := range expand(string(word)) {
+				fn([]byte(w), start, end)
+			}
+		}
+	}
+
+	if t.unicode {
+		t.tokenizeUnicode(batch, maxLen, emit)
+		return
+	}
+
+	wordBuf := make([]byte, 0, maxLen)
+	wordStart := -1
+	for i := 0; i < len(batch); i++ {
+		c, lower := batch[i], batch[i]
+		if c >= 'A' && c <= 'Z' {
+			lower += 32
+		}
+
+		if t.isWordByte(lower) {
+			if wordStart == -1 {
+				wordStart = i
+			}
+			if !t.keepCase {
+				c = lower
+			}
+			if len(wordBuf) < maxLen {
+				wordBuf = append(wordBuf, c)
+			}
+			continue
+		}
+
+		if wordStart == -1 {
+			continue
+		}
+
+		if joiner, size := t.joinerAt(batch, i); size > 0 {
+			if len(wordBuf) < maxLen {
+				wordBuf = append(wordBuf, joiner)
+			}
+			i += size - 1
+			continue
+		}
+
+		emit(wordBuf, wordStart, i)
+
+		wordBuf = wordBuf[:0]
+		wordStart = -1
+	}
+
+	if wordStart != -1 {
+		emit(wordBuf, wordStart, len(batch))
+	}
+}
+
+// tokenizeUnicode is the tokenize of utf-8 batches, maxLen is in bytes and
+// words are never truncated in the middle of a rune.
+func (t *tokenizer) tokenizeUnicode(batch []byte, maxLen int, fn func(word []byte, start, end int)) {
+	wordBuf := make([]byte, 0, maxLen)
+	wordStart := -1
+	for i := 0; i < len(batch); {
+		r, size := utf8.DecodeRune(batch[i:])
+		lower := unicode.ToLower(r)
+
+		// NOTE: combining marks are a part of the previous letter
+		if t.isWordRune(lower) || wordStart != -1 && unicode.IsMark(r) {
+			if wordStart == -1 {
+				wordStart = i
+			}
+			if !t.keepCase {
+				r = lower
+			}
+			if len(wordBuf)+utf8.RuneLen(r) <= maxLen {
+				wordBuf = append(wordBuf, string(r)...)
+			}
+			i += size
+			continue
+		}
+
+		if wordStart == -1 {
+			i += size
+			continue
+		}
+
+		if joiner, size := t.joinerAt(batch, i); size > 0 {
+			if len(wordBuf) < maxLen {
+				wordBuf = append(wordBuf, joiner)
+			}
+			i += size
+			continue
+		}
+
+		fn(wordBuf, wordStart, i)
+
+		wordBuf = wordBuf[:0]
+		wordStart = -1
+		i += size
+	}
+
+	if wordStart != -1 {
+		fn(wordBuf, wordStart, len(batch))
+	}
+}
+
+// isWordRune is isWordByte of unicode letters.
+func (t *tokenizer) isWordRune(r rune) bool {
+	switch {
+	case r < utf8.RuneSelf:
+		return t.isWordByte(byte(r))
+	case unicode.IsLetter(r):
+		return true
+	case unicode.IsDigit(r):
+		return t.digits
+	default:
+		return false
+	}
+}
+
+// isWordByte returns true if the lowercase byte is a part of a word.
+func (t *tokenizer) isWordByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return true
+	case c >= '0' && c <= '9':
+		return t.digits
+	case c == '_':
+		return t.underscores
+	default:
+		return false
+	}
+}
+
+// joinerAt returns a byte that joins two parts of a word at i and its size
+// in the batch, or 0 size if there is none.
+func (t *tokenizer) joinerAt(batch []byte, i int) (byte, int) {
+	// NOTE: apostrophe is a part of a word only between two letters
+	if t.apostrophes || t.contractions {
+		if size := apostropheAt(batch, i); size > 0 && isLetter(batch, i+size) {
+			return '\'', size
+		}
+	}
+
+	next := byte(0)
+	if i+1 < len(batch) {
+		next = batch[i+1]
+		if next >= 'A' && next <= 'Z' {
+			next += 32
+		}
+	}
+
+	switch {
+	case batch[i] == '-' && t.hyphens && t.isWordByte(next):
+		return '-', 1
+	case batch[i] == '.' && t.dots && t.isWordByte(next):
+		return '.', 1
+	default:
+		return 0, 0
+	}
+}
+
+// apostropheAt returns size of an apostrophe at i, or 0 if there is none.
+func apostropheAt(batch []byte, i int) int {
+	switch {
+	case batch[i] == '\'':
+		return 1
+	case bytes.HasPrefix(batch[i:], []byte("’")):
+		return 3
+	default:
+		return 0
+	}
+}
+
+func isLetter(batch []byte, i int) bool {
+	if i >= len(batch) {
+		return false
+	}
+	c := batch[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// contractions that can't be expanded by suffix rules.
+var contractionWords = map[string][]string{
+	"won't":   {"will", "not"},
+	"can't":   {"can", "not"},
+	"shan't":  {"shall", "not"},
+	"ain't":   {"is", "not"},
+	"let's":   {"let", "us"},
+	"it's":    {"it", "is"},
+	"he's":    {"he", "is"},
+	"she's":   {"she", "is"},
+	"that's":  {"that", "is"},
+	"what's":  {"what", "is"},
+	"who's":   {"who", "is"},
+	"there's": {"there", "is"},
+	"here's":  {"here", "is"},
+	"where's": {"where", "is"},
+	"how's":   {"how", "is"},
+}
+
+// contractionSuffixes are expanded for any word, "'s" is not among them,
+// because it's usually possessive.
+var contractionSuffixes = []struct {
+	suffix string
+	word   string
+}{
+	{"n't", "not"},
+	{"'re", "are"},
+	{"'ve", "have"},
+	{"'ll", "will"},
+	{"'d", "would"},
+	{"'m", "am"},
+}
+
+// expand returns words of a contraction, or the word itself.
+func expand(word string) []string {
+	if words, ok := contractionWords[strings.ToLower(word)]; ok {
+		return words
+	}
+
+	for _, s := range contractionSuffixes {
+		if len(word) > len(s.suffix) && strings.HasSuffix(strings.ToLower(word), s.suffix) {
+			return append(expand(word[:len(word)-len(s.suffix)]), s.word)
+		}
+	}
+
+	return []string{word}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tokenizer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		t        *tokenizer
+		expected []string
+	}{
+		{
+			name:     "default splits contractions",
+			input:    "don't it's",
+			expected: []string{"don", "t", "it", "s"},
+		},
+		{
+			name:     "contraction",
+			input:    "Don't stop",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"don't", "stop"},
+		},
+		{
+			name:     "unicode apostrophe",
+			input:    "it’s fine",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"it's", "fine"},
+		},
+		{
+			name:     "quotes are not apostrophes",
+			input:    "'quoted' ‘words’",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"quoted", "words"},
+		},
+		{
+			name:     "trailing apostrophe",
+			input:    "students' goin'",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"students", "goin"},
+		},
+		{
+			name:     "standalone letter",
+			input:    "rock 'n' roll",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"rock", "n", "roll"},
+		},
+		{
+			name:     "double apostrophe",
+			input:    "a''b",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "apostrophe at the end of the batch",
+			input:    "it'",
+			t:        &tokenizer{apostrophes: true},
+			expected: []string{"it"},
+		},
+		{
+			name:     "expand n't",
+			input:    "doesn't isn't",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"does", "not", "is", "not"},
+		},
+		{
+			name:     "expand irregular",
+			input:    "won't can’t let's",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"will", "not", "can", "not", "let", "us"},
+		},
+		{
+			name:     "expand pronoun is",
+			input:    "It's what's",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"it", "is", "what", "is"},
+		},
+		{
+			name:     "expand suffixes",
+			input:    "we're you've they'll I'm",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"we", "are", "you", "have", "they", "will", "i", "am"},
+		},
+		{
+			name:     "expand nested",
+			input:    "I'd've",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"i", "would", "have"},
+		},
+		{
+			name:     "possessive is not expanded",
+			input:    "John's o'clock",
+			t:        &tokenizer{contractions: true},
+			expected: []string{"john's", "o'clock"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			words := []string{}
+			tc.t.tokenize([]byte(tc.input), maxWordLen, func(word []byte, start, end int) {
+				words = append(words, string(word))
+			})
+			assert.Equal(t, tc.expected, words)
+		})
+	}
+}
+
+func Test_tokenizer__offsets(t *testing.T) {
+	input := "Don’t stop"
+
+	type token struct {
+		word       string
+		start, end int
+	}
+	tokens := []token{}
+	(&tokenizer{contractions: true}).tokenize([]byte(input), maxWordLen, func(word []byte, start, end int) {
+		tokens = append(tokens, token{string(word), start, end})
+	})
+
+	assert.Equal(t, []token{
+		{"do", 0, 7},
+		{"not", 0, 7},
+		{"stop", 8, 12},
+	}, tokens)
+}
+
+func Test_tokenizer__classes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		t        *tokenizer
+		expected []string
+	}{
+		{
+			name:     "zero tokenizer",
+			input:    "well-known error_code v2 os.path",
+			t:        &tokenizer{},
+			expected: []string{"well", "known", "error", "code", "v", "os", "path"},
+		},
+		{
+			name:     "hyphens",
+			input:    "well-known e-mail - -flag end-",
+			t:        &tokenizer{hyphens: true},
+			expected: []string{"well-known", "e-mail", "flag", "end"},
+		},
+		{
+			name:     "underscores",
+			input:    "error_code __init__ _",
+			t:        &tokenizer{underscores: true},
+			expected: []string{"error_code", "__init__", "_"},
+		},
+		{
+			name:     "digits",
+			input:    "v2 covid19 404",
+			t:        &tokenizer{digits: true},
+			expected: []string{"v2", "covid19", "404"},
+		},
+		{
+			name:     "dots",
+			input:    "os.path Example.COM. the end.",
+			t:        &tokenizer{dots: true},
+			expected: []string{"os.path", "example.com", "the", "end"},
+		},
+		{
+			name:     "digits are joined only if enabled",
+			input:    "v1.2-rc3",
+			t:        &tokenizer{dots: true, hyphens: true},
+			expected: []string{"v", "rc"},
+		},
+		{
+			name:     "all classes",
+			input:    "v1.2-rc3 user_id=42 don't",
+			t:        &tokenizer{dots: true, hyphens: true, digits: true, underscores: true, apostrophes: true},
+			expected: []string{"v1.2-rc3", "user_id", "42", "don't"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			words := []string{}
+			tc.t.tokenize([]byte(tc.input), maxWordLen, func(word []byte, start, end int) {
+				words = append(words, string(word))
+			})
+			assert.Equal(t, tc.expected, words)
+		})
+	}
+}
+
+func Test_tokenizer__unicode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		t        *tokenizer
+		maxLen   int
+		expected []string
+	}{
+		{
+			name:     "letters",
+			input:    "Café NAÏVE straße, Ελλάδα и Москва",
+			t:        &tokenizer{unicode: true},
+			expected: []string{"café", "naïve", "straße", "ελλάδα", "и", "москва"},
+		},
+		{
+			name:     "combining marks",
+			input:    "café ́x",
+			t:        &tokenizer{unicode: true},
+			expected: []string{"café", "x"},
+		},
+		{
+			name:     "byte order mark",
+			input:    "\ufeffthe end",
+			t:        &tokenizer{unicode: true},
+			expected: []string{"the", "end"},
+		},
+		{
+			name:     "options",
+			input:    "L’été don’t Ünïcode_2 x-ray",
+			t:        &tokenizer{unicode: true, apostrophes: true, underscores: true, digits: true, hyphens: true, keepCase: true},
+			expected: []string{"L", "été", "don't", "Ünïcode_2", "x-ray"},
+		},
+		{
+			name:     "truncated at rune boundary",
+			input:    "éééé",
+			t:        &tokenizer{unicode: true},
+			maxLen:   5,
+			expected: []string{"éé"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maxLen := tc.maxLen
+			if maxLen == 0 {
+				maxLen = maxWordLen
+			}
+
+			words := []string{}
+			tc.t.tokenize([]byte(tc.input), maxLen, func(word []byte, start, end int) {
+				words = append(words, string(word))
+			})
+			assert.Equal(t, tc.expected, words)
+		})
+	}
+}
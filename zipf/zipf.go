@@ -0,0 +1,125 @@
+// Package zipf fits word frequencies to Zipf's law.
+//
+// https://en.wikipedia.org/wiki/Zipf%27s_law
+package zipf
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/ngalaiko/words/count"
+)
+
+// Rank is a single row of a rank-frequency table.
+type Rank struct {
+	Rank  int
+	Word  string
+	Count uint64
+}
+
+// Table returns rank-frequency table of the words, the most frequent word
+// has rank 1.
+func Table(words []count.Element) []Rank {
+	sorted := make([]count.Element, len(words))
+	copy(sorted, words)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	table := make([]Rank, 0, len(sorted))
+	for i, w := range sorted {
+		table = append(table, Rank{
+			Rank:  i + 1,
+			Word:  w.Key,
+			Count: w.Count,
+		})
+	}
+	return table
+}
+
+// Fit is a result of fitting a table to f(r) = C / r^s.
+type Fit struct {
+	// Exponent is s, it's close to 1 for natural languages.
+	Exponent float64
+	// Intercept is log(C).
+	Intercept float64
+	// RSquared is a coefficient of determination of the fit on log-log data,
+	// 1 is a perfect fit.
+	RSquared float64
+	// Points is a number of fitted ranks.
+	Points int
+}
+
+// FitTable fits the table using least squares on log(rank), log(count).
+func FitTable(table []Rank) *Fit {
+	fit := &Fit{Points: len(table)}
+	if len(table) < 2 {
+		return fit
+	}
+
+	n := float64(len(table))
+	var sumX, sumY, sumXX, sumXY float64
+	for _, r := range table {
+		x, y := math.Log(float64(r.Rank)), math.Log(float64(r.Count))
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return fit
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	meanY := sumY / n
+	var ssTotal, ssResidual float64
+	for _, r := range table {
+		x, y := math.Log(float64(r.Rank)), math.Log(float64(r.Count))
+		predicted := intercept + slope*x
+		ssTotal += (y - meanY) * (y - meanY)
+		ssResidual += (y - predicted) * (y - predicted)
+	}
+
+	fit.Exponent = -slope
+	fit.Intercept = intercept
+	fit.RSquared = 1
+	if ssTotal > 0 {
+		fit.RSquared = 1 - ssResidual/ssTotal
+	}
+	return fit
+}
+
+func (f *Fit) String() string {
+	return fmt.Sprintf("zipf exponent: %.4f\nintercept: %.4f\nr squared: %.4f\nranks: %d\n",
+		f.Exponent, f.Intercept, f.RSquared, f.Points)
+}
+
+// WriteCSV writes the table to w as csv with a header.
+func WriteCSV(w io.Writer, table []Rank) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"rank", "word", "count"}); err != nil {
+		return err
+	}
+	for _, r := range table {
+		if err := cw.Write([]string{
+			strconv.Itoa(r.Rank),
+			r.Word,
+			strconv.FormatUint(r.Count, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
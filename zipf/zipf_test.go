@@ -0,0 +1,59 @@
+package zipf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ngalaiko/words/count"
+)
+
+func Test_Table(t *testing.T) {
+	assert.Equal(t, []Rank{
+		{Rank: 1, Word: "the", Count: 3},
+		{Rank: 2, Word: "a", Count: 1},
+		{Rank: 3, Word: "of", Count: 1},
+	}, Table([]count.Element{
+		{Key: "of", Count: 1},
+		{Key: "the", Count: 3},
+		{Key: "a", Count: 1},
+	}))
+}
+
+func Test_FitTable(t *testing.T) {
+	// NOTE: f(r) = 60 / r
+	fit := FitTable(Table([]count.Element{
+		{Key: "a", Count: 60},
+		{Key: "b", Count: 30},
+		{Key: "c", Count: 20},
+		{Key: "d", Count: 15},
+		{Key: "e", Count: 12},
+		{Key: "f", Count: 10},
+	}))
+
+	assert.Equal(t, 6, fit.Points)
+	assert.InDelta(t, 1, fit.Exponent, 1e-9)
+	assert.InDelta(t, 4.0943, fit.Intercept, 1e-4)
+	assert.InDelta(t, 1, fit.RSquared, 1e-9)
+}
+
+func Test_FitTable__flat(t *testing.T) {
+	fit := FitTable(Table([]count.Element{
+		{Key: "a", Count: 5},
+		{Key: "b", Count: 5},
+		{Key: "c", Count: 5},
+	}))
+
+	assert.InDelta(t, 0, fit.Exponent, 1e-9)
+	assert.InDelta(t, 1, fit.RSquared, 1e-9)
+}
+
+func Test_WriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteCSV(buf, []Rank{
+		{Rank: 1, Word: "the", Count: 3},
+		{Rank: 2, Word: "of", Count: 1},
+	}))
+	assert.Equal(t, "rank,word,count\n1,the,3\n2,of,1\n", buf.String())
+}